@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/IBM/sarama"
 	"github.com/philipparndt/go-logger"
@@ -9,142 +11,409 @@ import (
 	"gokcat/internal/kafka"
 	"gokcat/internal/kafka/schemaRegistry"
 	"gokcat/message"
+	"io"
+	"os"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
-func runCat(topic string, cfg config.Config, follow bool) {
-	partition := int32(0)
-	tlsConfig, err := kafka.NewTLSConfig(cfg.Certs.ClientCert, cfg.Certs.ClientKey, cfg.Certs.Ca, cfg.Certs.Insecure)
-	if err != nil {
-		logger.Panic("Failed to create TLS config", err)
-	}
+type catOptions struct {
+	follow     bool
+	partitions []int32
+	start      kafka.StartPosition
+	limits     kafka.Limits
+	output     string
+	format     string
+	filter     *message.Filter
+	validate   bool
+	rendering  string
+	group      groupOptions
+	schemas    schemaFileFlags
+}
 
-	kConfig := sarama.NewConfig()
-	kConfig.Net.TLS.Enable = true
-	kConfig.Net.TLS.Config = tlsConfig
+// schemaFileFlags are local Avro schema files used instead of the Schema Registry, and the reader schema of the values
+type schemaFileFlags struct {
+	byID          []string
+	value         string
+	key           string
+	readerSubject string
+	readerVersion string
+	readerFile    string
+}
+
+func runCat(topic string, cfg config.Config, opts catOptions) {
+	kConfig := newSaramaConfig(cfg)
+
+	if opts.group.id != "" {
+		configureGroup(kConfig, opts.group)
+	}
 
-	sr := schemaRegistry.New(cfg.SchemaRegistry.Url,
-		cfg.SchemaRegistry.Username,
-		cfg.SchemaRegistry.Password,
-		cfg.SchemaRegistry.Insecure,
-	)
+	sr := newSchemaRegistry(cfg)
 
 	deserializer := sr.NewDeserializer()
-	logger.Debug("Created deserializer successfully")
+	deserializer.ValidateJSON = opts.validate
+	deserializer.AvroRendering = opts.rendering
+
+	valueSchema, keySchema, err := loadSchemaFiles(&deserializer, opts.schemas)
+	if err != nil {
+		logger.Panic("Failed to load schema files", err)
+	}
 
-	client, err := sarama.NewClient([]string{cfg.Broker}, kConfig)
+	readerSchema, err := loadReaderSchema(&deserializer, opts.schemas)
 	if err != nil {
-		logger.Panic("Failed to create client", err)
+		logger.Panic("Failed to load reader schema", err)
 	}
+	logger.Debug("Created deserializer successfully")
+
+	client := newClient(cfg, kConfig)
 	defer client.Close()
 
+	writer, err := newWriter(opts)
+	if err != nil {
+		logger.Panic("Failed to create output writer", err)
+	}
+	defer closeWriter(writer)
+
+	sink := &catSink{
+		topic:        topic,
+		deserializer: deserializer,
+		valueSchema:  valueSchema,
+		keySchema:    keySchema,
+		readerSchema: readerSchema,
+		writer:       writer,
+		filter:       opts.filter,
+		limits:       opts.limits,
+	}
+
+	if opts.group.id != "" {
+		runCatGroup(client, topic, opts, sink)
+	} else {
+		runCatPartitions(client, topic, opts, sink)
+	}
+
+	if sink.done() {
+		logger.Info("Reached message limit of", strconv.Itoa(opts.limits.Count), "messages. Exiting.")
+	} else if !opts.follow {
+		logger.Info("Reached end of topic. Exiting.")
+	}
+}
+
+// runCatPartitions consumes the selected partitions of the topic without a consumer group
+func runCatPartitions(client sarama.Client, topic string, opts catOptions, sink *catSink) {
 	consumer, err := sarama.NewConsumerFromClient(client)
 	if err != nil {
 		logger.Panic("Failed to create consumer from client", err)
 	}
 	defer consumer.Close()
 
-	latestOffset, err := getOffset(client, partition)
+	partitions, err := kafka.SelectPartitions(client, topic, opts.partitions)
 	if err != nil {
-		logger.Panic("Failed to get latest offset", err)
+		logger.Panic("Failed to select partitions", err)
 	}
 
-	if latestOffset == 0 {
-		logger.Info("No messages found in topic", topic)
-		if !follow {
-			fmt.Println("[]")
-			return
-		}
+	ranges, err := getPartitionRanges(client, topic, partitions, opts)
+	if err != nil {
+		logger.Panic("Failed to get partition offsets", err)
+	}
+
+	if len(ranges) == 0 {
+		logger.Info("No messages to consume in topic", topic)
+		return
 	}
 
-	if follow {
-		latestOffset = -1
+	if opts.follow {
 		logger.Info("Following topic, press Ctrl+C to exit")
-	} else {
-		logger.Info("Consuming until offset", strconv.Itoa(int(latestOffset)))
 	}
 
-	pc, err := consumer.ConsumePartition(topic, partition, sarama.OffsetOldest)
+	done := make(chan struct{})
+	defer close(done)
+
+	messages, err := kafka.ConsumePartitions(consumer, topic, ranges, done)
 	if err != nil {
-		logger.Panic("Failed to consume partition", err)
+		logger.Panic("Failed to consume partitions", err)
+	}
+
+	for msg := range messages {
+		sink.handle(msg)
+		if sink.done() {
+			break
+		}
+	}
+}
+
+// catSink decodes, filters and writes the consumed messages
+type catSink struct {
+	topic        string
+	deserializer schemaRegistry.Deserializer
+	valueSchema  *schemaRegistry.Schema
+	keySchema    *schemaRegistry.Schema
+	readerSchema *schemaRegistry.Schema
+	writer       message.Writer
+	filter       *message.Filter
+	limits       kafka.Limits
+	processed    int
+	count        int
+}
+
+// handle processes a message and reports whether it was handled: written to the output or filtered out.
+// It is false if the message could not be filtered or written.
+func (s *catSink) handle(msg *sarama.ConsumerMessage) bool {
+	if s.processed%1000 == 0 && s.processed > 0 {
+		logger.Debug("Processed " + strconv.Itoa(s.processed) + " messages")
+	}
+	s.processed++
+
+	schema, payloadData, err := s.decodeValue(msg.Value)
+	var validationErr *schemaRegistry.ValidationError
+	var resolutionErr *schemaRegistry.ResolutionError
+	if err != nil && !errors.As(err, &validationErr) && !errors.As(err, &resolutionErr) {
+		logger.Panic("Failed to decode message", err)
+	}
+
+	var encoding string
+	if schema == nil {
+		payloadData, encoding = decodePlain(msg.Value)
 	}
-	defer pc.Close()
 
-	fmt.Println("[")
+	out := message.New(schema, payloadData, msg)
+	out.PayloadEncoding = encoding
+	if err != nil {
+		out.Error = err.Error()
+	}
+	if resolutionErr != nil {
+		logger.Warn("Message does not match the reader schema", "offset", msg.Offset, "partition", msg.Partition, "error", err)
+	}
 
-	ctr := 0
+	if msg.Key != nil && (s.keySchema != nil || schemaRegistry.IsFramed(msg.Key)) {
+		keySchema, key, err := s.decodeKey(msg.Key)
+		var keyValidationErr *schemaRegistry.ValidationError
+		switch {
+		case errors.As(err, &keyValidationErr):
+			logger.Warn("Key does not match its schema", "offset", msg.Offset, "partition", msg.Partition, "error", err)
+			out.SetKey(keySchema, key)
+		case err != nil:
+			logger.Warn("Failed to decode key, using raw key", "offset", msg.Offset, "partition", msg.Partition, "error", err)
+		case keySchema != nil:
+			out.SetKey(keySchema, key)
+		}
+	}
 
-	for msg := range pc.Messages() {
-		if ctr%1000 == 0 && ctr > 0 {
-			logger.Debug("Processed " + strconv.Itoa(ctr) + " messages")
+	if s.filter != nil {
+		match, err := s.filter.Match(out)
+		if err != nil {
+			logger.Error("Failed to filter message", "offset", msg.Offset, "partition", msg.Partition, "error", err)
+			return false
 		}
-		ctr++
-
-		var payloadData interface{} = nil
-		var schema *schemaRegistry.Schema
-
-		// Check if the message starts with magic byte (0x0 for Confluent wire format)
-		if len(msg.Value) < 5 || msg.Value[0] != 0x0 {
-			payloadDataString := decodeBase64OrRaw(msg.Value)
-			payloadData = decodeJSONOrRaw(payloadDataString)
-		} else {
-			schema, err = deserializer.LoadSchemaInfo(topic, msg)
-			if err != nil {
-				logger.Panic("Failed to load schema info", err)
-			}
-
-			payloadData = deserializer.Deserialize(schema, msg.Value[5:])
+		if !match {
+			return true
 		}
+	}
+
+	if err := s.writer.Write(out); err != nil {
+		logger.Error("Failed to write message", "error", err)
+		return false
+	}
+	s.count++
 
-		out := message.New(schema, payloadData, msg)
+	return true
+}
 
-		jsonPayload, err := json.MarshalIndent(out, "", "  ")
+// done reports whether the message limit of the whole run is reached
+func (s *catSink) done() bool {
+	return s.limits.Reached(s.count)
+}
+
+// newWriter creates the output writer, a format template takes precedence over the output mode
+func newWriter(opts catOptions) (message.Writer, error) {
+	if opts.format != "" {
+		return message.NewTemplateWriter(opts.format, os.Stdout)
+	}
+	return message.NewWriter(opts.output, os.Stdout)
+}
+
+func closeWriter(writer message.Writer) {
+	if err := writer.Close(); err != nil {
+		logger.Error("Failed to close output", "error", err)
+	}
+}
+
+// decodeValue decodes a message value with the forced value schema if given, otherwise by its framing
+func (s *catSink) decodeValue(data []byte) (*schemaRegistry.Schema, interface{}, error) {
+	if s.valueSchema != nil {
+		payload, err := s.deserializer.DeserializeUnframedAs(s.readerSchema, s.valueSchema, data)
+		return s.valueSchema, payload, err
+	}
+	return decodeValue(s.deserializer, s.topic, s.readerSchema, data)
+}
+
+// decodeKey decodes a framed message key with a known schema ID, or any key with the forced key schema if given.
+// The schema is nil if the key is not decoded.
+func (s *catSink) decodeKey(data []byte) (*schemaRegistry.Schema, interface{}, error) {
+	if s.keySchema != nil {
+		payload, err := s.deserializer.DeserializeUnframed(s.keySchema, data)
+		return s.keySchema, payload, err
+	}
+	if !s.deserializer.IsKnownKeySchema(s.topic, data) {
+		return nil, nil, nil
+	}
+	return decodeFramed(s.deserializer, s.topic, nil, data)
+}
+
+// loadSchemaFiles maps the schema IDs to their files and loads the schemas forced for values and keys
+func loadSchemaFiles(deserializer *schemaRegistry.Deserializer, flags schemaFileFlags) (*schemaRegistry.Schema, *schemaRegistry.Schema, error) {
+	for _, value := range flags.byID {
+		id, file, err := parseSchemaFileMapping(value)
 		if err != nil {
-			logger.Error("Failed to marshal payload data to JSON", "error", err)
-			continue
+			return nil, nil, err
+		}
+		if err := deserializer.AddSchemaFile(id, file); err != nil {
+			return nil, nil, err
 		}
-		fmt.Print(string(jsonPayload))
+	}
 
-		if msg.Offset >= latestOffset-1 && !follow {
-			logger.Info("Reached end of topic. Exiting.")
-			break
+	var valueSchema, keySchema *schemaRegistry.Schema
+	var err error
+	if flags.value != "" {
+		if valueSchema, err = deserializer.LoadSchemaFile(flags.value); err != nil {
+			return nil, nil, err
+		}
+	}
+	if flags.key != "" {
+		if keySchema, err = deserializer.LoadSchemaFile(flags.key); err != nil {
+			return nil, nil, err
 		}
+	}
+	return valueSchema, keySchema, nil
+}
 
-		fmt.Println(",")
+// loadReaderSchema loads the reader schema of the values from a subject or a file, nil if none is given
+func loadReaderSchema(deserializer *schemaRegistry.Deserializer, flags schemaFileFlags) (*schemaRegistry.Schema, error) {
+	var schema *schemaRegistry.Schema
+	var err error
+	switch {
+	case flags.readerSubject != "":
+		schema, err = deserializer.LoadSubjectSchema(flags.readerSubject, flags.readerVersion)
+	case flags.readerFile != "":
+		schema, err = deserializer.LoadSchemaFile(flags.readerFile)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Println()
-	fmt.Println("]")
+	if schema.SchemaType != schemaRegistry.TypeAvro {
+		return nil, fmt.Errorf("reader schema must be an Avro schema, got %s", schema.SchemaType)
+	}
+	return schema, nil
 }
 
-// decodeBase64OrRaw tries to decode the input as base64, returns raw bytes if not base64
-func decodeBase64OrRaw(data []byte) []byte {
-	decoded, err := strconv.Unquote("\"" + string(data) + "\"")
-	if err == nil {
-		return []byte(decoded)
+// parseSchemaFileMapping parses ID=path
+func parseSchemaFileMapping(value string) (int, string, error) {
+	idText, file, ok := strings.Cut(value, "=")
+	id, err := strconv.Atoi(idText)
+	if !ok || err != nil || id < 0 || file == "" {
+		return 0, "", fmt.Errorf("invalid schema file %q, expected ID=path", value)
 	}
-	return data
+	return id, file, nil
 }
 
-// decodeJSONOrRaw tries to decode the input as JSON, returns raw bytes if not JSON
-func decodeJSONOrRaw(data []byte) interface{} {
-	var v interface{}
-	if err := json.Unmarshal(data, &v); err == nil {
-		return v
+// decodeValue decodes a message value with its schema if it uses the Confluent wire format.
+// Other values are not decoded and returned without a schema, see decodePlain.
+func decodeValue(deserializer schemaRegistry.Deserializer, topic string, reader *schemaRegistry.Schema, data []byte) (*schemaRegistry.Schema, interface{}, error) {
+	if !schemaRegistry.IsFramed(data) {
+		return nil, nil, nil
 	}
-	return data
+	return decodeFramed(deserializer, topic, reader, data)
 }
 
-func getOffset(client sarama.Client, partition int32) (int64, error) {
-	// Get the latest offset (the "high watermark")
-	latestOffset, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+// decodeFramed decodes Confluent wire format data (magic byte, 4-byte schema ID, payload), as the reader schema if given.
+// On a *schemaRegistry.ValidationError or *schemaRegistry.ResolutionError the decoded payload is returned as well.
+func decodeFramed(deserializer schemaRegistry.Deserializer, topic string, reader *schemaRegistry.Schema, data []byte) (*schemaRegistry.Schema, interface{}, error) {
+	schema, err := deserializer.LoadSchemaInfo(topic, data)
 	if err != nil {
-		return 0, err
+		return nil, nil, err
+	}
+
+	payload, err := deserializer.DeserializeAs(reader, schema, data[5:])
+	return schema, payload, err
+}
+
+// decodePlain decodes a value without schema as JSON, or as escaped JSON (e.g. {\"id\":1}).
+// Other values are returned as text, or as raw bytes if they are not valid UTF-8.
+// The encoding tells gokcat produce how to restore the original value.
+func decodePlain(data []byte) (interface{}, string) {
+	if data == nil {
+		return nil, ""
 	}
+	if v, ok := decodeJSON(data); ok {
+		return v, ""
+	}
+	if unquoted, err := strconv.Unquote("\"" + string(data) + "\""); err == nil {
+		if v, ok := decodeJSON([]byte(unquoted)); ok {
+			return v, message.EncodingEscapedJSON
+		}
+	}
+	if utf8.Valid(data) {
+		return string(data), message.EncodingText
+	}
+	return data, message.EncodingBase64
+}
+
+// decodeJSON decodes a single JSON value, numbers are kept as written
+func decodeJSON(data []byte) (interface{}, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, false
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, false
+	}
+	return v, true
+}
+
+// getPartitionRanges determines the offsets to consume for each partition.
+// In follow mode partitions are consumed until the limits are reached, otherwise each one also stops at its own high watermark.
+func getPartitionRanges(client sarama.Client, topic string, partitions []int32, opts catOptions) ([]kafka.PartitionRange, error) {
+	ranges := make([]kafka.PartitionRange, 0, len(partitions))
+	for _, partition := range partitions {
+		oldest, newest, err := kafka.Watermarks(client, topic, partition)
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := opts.start.Resolve(client, topic, partition, oldest, newest)
+		if err != nil {
+			return nil, err
+		}
+
+		r := kafka.PartitionRange{
+			Partition: partition,
+			Start:     start,
+			End:       -1,
+		}
+
+		if !opts.follow {
+			r.End = newest - 1
+		}
+
+		r = opts.limits.Apply(r)
+
+		if r.End >= 0 && start > r.End {
+			logger.Debug("No messages to consume in partition", strconv.Itoa(int(partition)))
+			continue
+		}
+
+		if r.End >= 0 {
+			logger.Info("Consuming partition", strconv.Itoa(int(partition)), "from offset", strconv.Itoa(int(r.Start)), "until offset", strconv.Itoa(int(r.End)))
+		}
 
-	if latestOffset == 0 {
-		return 0, nil
+		ranges = append(ranges, r)
 	}
 
-	return latestOffset - 1, nil
+	return ranges, nil
 }
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/philipparndt/go-logger"
+	"gokcat/config"
+	"gokcat/internal/kafka"
+	"gokcat/internal/kafka/schemaRegistry"
+	"os"
+	"path/filepath"
+)
+
+// newSaramaConfig creates the sarama config shared by all commands
+func newSaramaConfig(cfg config.Config) *sarama.Config {
+	kConfig, err := kafka.NewConfig(cfg)
+	if err != nil {
+		logger.Panic("Failed to create Kafka config", err)
+	}
+	return kConfig
+}
+
+func newClient(cfg config.Config, kConfig *sarama.Config) sarama.Client {
+	client, err := kafka.NewClient(cfg, kConfig)
+	if err != nil {
+		logger.Panic("Failed to create client", err)
+	}
+	return client
+}
+
+// newClusterAdmin creates a client and a cluster admin on top of it. Closing the admin also closes the client.
+func newClusterAdmin(cfg config.Config) (sarama.Client, sarama.ClusterAdmin) {
+	client, admin, err := kafka.NewClusterAdmin(cfg)
+	if err != nil {
+		logger.Panic("Failed to create cluster admin", err)
+	}
+	return client, admin
+}
+
+// newSchemaRegistry creates the Schema Registry client. Schemas are cached in the configured cache directory,
+// or in the directory of the system alias.
+func newSchemaRegistry(cfg config.Config) schemaRegistry.Client {
+	sr := schemaRegistry.New(cfg.SchemaRegistry.Url,
+		cfg.SchemaRegistry.Username,
+		cfg.SchemaRegistry.Password,
+		cfg.SchemaRegistry.Insecure,
+	)
+
+	sr.CacheDir = cfg.SchemaRegistry.CacheDir
+	if sr.CacheDir == "" && systemAlias != "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			sr.CacheDir = filepath.Join(home, ".config", "gokcat", systemAlias, "schemas")
+		}
+	}
+
+	sr.Offline = offline
+	if offline && sr.CacheDir == "" {
+		logger.Panic("Offline mode requires a schema cache, use a system alias or set schemaRegistry.cacheDir")
+	}
+
+	return sr
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/philipparndt/go-logger"
+	"gokcat/internal/kafka"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// CommitAuto marks messages as soon as they are received and commits them periodically
+	CommitAuto = "auto"
+	// CommitAfterOutput marks messages after they were written and commits them periodically
+	CommitAfterOutput = "after-output"
+	// CommitNever does not commit any offsets
+	CommitNever = "never"
+)
+
+var commitModes = []string{CommitAuto, CommitAfterOutput, CommitNever}
+
+var initialOffsets = map[string]int64{
+	"oldest": sarama.OffsetOldest,
+	"newest": sarama.OffsetNewest,
+}
+
+type groupOptions struct {
+	id            string
+	commit        string
+	initialOffset string
+}
+
+// configureGroup applies the consumer group settings, it must be called before the client is created
+func configureGroup(kConfig *sarama.Config, opts groupOptions) {
+	kConfig.Consumer.Offsets.Initial = initialOffsets[opts.initialOffset]
+	kConfig.Consumer.Offsets.AutoCommit.Enable = opts.commit != CommitNever
+}
+
+// runCatGroup consumes the topic as a member of a consumer group until the limits are reached,
+// all assigned partitions reached their high watermark (unless following) or the process is interrupted
+func runCatGroup(client sarama.Client, topic string, opts catOptions, sink *catSink) {
+	group, err := sarama.NewConsumerGroupFromClient(opts.group.id, client)
+	if err != nil {
+		logger.Panic("Failed to create consumer group", err)
+	}
+	defer func() {
+		if err := group.Close(); err != nil {
+			logger.Error("Failed to close consumer group", "error", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	handler := &groupHandler{
+		client: client,
+		opts:   opts,
+		sink:   sink,
+		cancel: cancel,
+	}
+
+	if opts.follow {
+		logger.Info("Following topic in consumer group", opts.group.id, ", press Ctrl+C to exit")
+	}
+
+	for {
+		if err := group.Consume(ctx, []string{topic}, handler); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return
+			}
+			logger.Panic("Failed to consume in consumer group", err)
+		}
+
+		if ctx.Err() != nil || handler.completed {
+			return
+		}
+		logger.Debug("Consumer group session ended, rejoining")
+	}
+}
+
+// groupHandler handles the claims of a consumer group session.
+// Claims are consumed concurrently, so messages are passed to the sink under a lock.
+type groupHandler struct {
+	client sarama.Client
+	opts   catOptions
+	sink   *catSink
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	ends      map[int32]int64
+	starts    map[int32]int64
+	oldest    map[int32]int64
+	remaining int
+	completed bool
+}
+
+func (h *groupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.ends = make(map[int32]int64)
+	h.starts = make(map[int32]int64)
+	h.oldest = make(map[int32]int64)
+	h.completed = false
+	h.remaining = 0
+
+	for topic, partitions := range session.Claims() {
+		logger.Info("Assigned partitions of topic", topic, fmt.Sprint(partitions))
+		if h.opts.follow {
+			continue
+		}
+
+		for _, partition := range partitions {
+			oldest, newest, err := kafka.Watermarks(h.client, topic, partition)
+			if err != nil {
+				return err
+			}
+			h.ends[partition] = newest - 1
+			h.oldest[partition] = oldest
+			// where sarama starts without a valid committed offset
+			h.starts[partition] = oldest
+			if h.opts.group.initialOffset == "newest" {
+				h.starts[partition] = newest
+			}
+			h.remaining++
+		}
+	}
+
+	if !h.opts.follow && h.remaining == 0 {
+		h.completed = true
+		h.cancel()
+	}
+
+	return nil
+}
+
+func (h *groupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	if h.opts.group.commit != CommitNever {
+		session.Commit()
+	}
+	return nil
+}
+
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	h.mu.Lock()
+	end, bounded := h.ends[claim.Partition()]
+	start := claim.InitialOffset()
+	// Without a committed offset the initial offset is sarama.OffsetOldest or sarama.OffsetNewest,
+	// a committed offset before the oldest is reset as well
+	if bounded && start < h.oldest[claim.Partition()] {
+		start = h.starts[claim.Partition()]
+	}
+	h.mu.Unlock()
+
+	if bounded && start > end {
+		logger.Debug("No messages to consume in partition", strconv.Itoa(int(claim.Partition())))
+		h.finishPartition()
+		return nil
+	}
+
+	idle := time.NewTicker(kafka.IdleInterval)
+	defer idle.Stop()
+	active := false
+	position := start
+	failed := false
+
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			active = true
+			position = msg.Offset + 1
+
+			h.mu.Lock()
+			if h.sink.done() {
+				// another claim reached the message limit
+				h.mu.Unlock()
+				return nil
+			}
+
+			if h.opts.group.commit == CommitAuto {
+				session.MarkMessage(msg, "")
+			}
+
+			handled := h.sink.handle(msg)
+			done := h.sink.done()
+			h.mu.Unlock()
+
+			// Messages that failed to be written are not committed, so that they are read again.
+			// Marking a later message would commit them as well, so marking stops with the first failure.
+			if !handled {
+				failed = true
+			}
+			if h.opts.group.commit == CommitAfterOutput && !failed {
+				session.MarkMessage(msg, "")
+			}
+
+			if done {
+				h.mu.Lock()
+				h.completed = true
+				h.mu.Unlock()
+				h.cancel()
+				return nil
+			}
+
+			if bounded && msg.Offset >= end {
+				h.finishPartition()
+				return nil
+			}
+		case <-idle.C:
+			if bounded && !active && kafka.EndReached(end, position, claim.HighWaterMarkOffset()) {
+				h.finishPartition()
+				return nil
+			}
+			active = false
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// finishPartition records that a claimed partition reached its end, the run completes with the last one
+func (h *groupHandler) finishPartition() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remaining--
+	if h.remaining <= 0 {
+		h.completed = true
+		h.cancel()
+	}
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/philipparndt/go-logger"
+	"gokcat/config"
+	"gokcat/internal/kafka"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// groupsCmd represents the groups command
+var groupsCmd = &cobra.Command{
+	Use:   "groups",
+	Short: "Inspect consumer groups",
+	Long:  `List consumer groups and describe their members, committed offsets and lag.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configFile == "" && systemAlias == "" {
+			return errors.New("you must specify a config file or system alias")
+		}
+		return nil
+	},
+}
+
+var groupsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all consumer groups",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runGroupsList(cfg)
+	},
+}
+
+var groupsDescribeCmd = &cobra.Command{
+	Use:   "describe <group>",
+	Short: "Describe the members, offsets and lag of a consumer group",
+	Long: `Describe a consumer group: its members with client ID, host and assigned partitions,
+and the committed offset, log-end offset and lag of each partition with totals per topic.
+With --watch the description is redrawn on an interval until interrupted.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runGroupsDescribe(args[0], cfg, groupsWatch, groupsInterval)
+	},
+}
+
+var groupsWatch bool
+var groupsInterval time.Duration
+
+func init() {
+	rootCmd.AddCommand(groupsCmd)
+	groupsCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to the configuration file")
+	groupsCmd.PersistentFlags().StringVarP(&systemAlias, "systemAlias", "s", "", "System alias")
+	groupsCmd.PersistentFlags().StringVar(&brokers, "brokers", "", "Comma-separated bootstrap brokers, overrides the brokers of the config")
+
+	groupsCmd.AddCommand(groupsListCmd)
+	groupsListCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the groups in JSON format")
+
+	groupsCmd.AddCommand(groupsDescribeCmd)
+	groupsDescribeCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the description in JSON format")
+	groupsDescribeCmd.Flags().BoolVarP(&groupsWatch, "watch", "w", false, "Redraw the description on an interval")
+	groupsDescribeCmd.Flags().DurationVar(&groupsInterval, "interval", 5*time.Second, "Refresh interval of --watch")
+}
+
+func runGroupsList(cfg config.Config) {
+	_, admin := newClusterAdmin(cfg)
+	defer admin.Close()
+
+	groups, err := kafka.ListGroups(admin)
+	if err != nil {
+		logger.Panic("Failed to list consumer groups", err)
+	}
+
+	if jsonOutput {
+		printJSON(groups)
+		return
+	}
+
+	if len(groups) == 0 {
+		logger.Info("No consumer groups found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tSTATE\tPROTOCOL TYPE\tMEMBERS")
+	for _, g := range groups {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", g.Group, g.State, g.ProtocolType, g.Members)
+	}
+	_ = w.Flush()
+}
+
+func runGroupsDescribe(group string, cfg config.Config, watch bool, interval time.Duration) {
+	client, admin := newClusterAdmin(cfg)
+	defer admin.Close()
+
+	if !watch {
+		description, err := kafka.DescribeGroup(client, admin, group)
+		if err != nil {
+			logger.Panic("Failed to describe consumer group", err)
+		}
+		printGroupDescription(description)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		description, err := kafka.DescribeGroup(client, admin, group)
+		if !jsonOutput {
+			// Clear the screen and move the cursor to the top left
+			fmt.Print("\033[H\033[2J")
+		}
+		if err != nil {
+			logger.Error("Failed to describe consumer group", err)
+		} else {
+			printGroupDescription(description)
+		}
+		if !jsonOutput {
+			fmt.Printf("\nUpdated %s, refreshing every %s, press Ctrl+C to exit\n", time.Now().Format(time.TimeOnly), interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func printGroupDescription(description kafka.GroupDescription) {
+	if jsonOutput {
+		printJSON(description)
+		return
+	}
+
+	fmt.Println("Group:", description.Group)
+	fmt.Println("State:", description.State)
+	if description.Protocol != "" {
+		fmt.Println("Protocol:", description.Protocol)
+	}
+	fmt.Println()
+
+	if len(description.Members) == 0 {
+		fmt.Println("No active members")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MEMBER\tCLIENT ID\tHOST\tASSIGNMENT")
+		for _, m := range description.Members {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.MemberID, m.ClientID, m.Host, formatAssignment(m.Assignment))
+		}
+		_ = w.Flush()
+	}
+	fmt.Println()
+
+	if len(description.Topics) == 0 {
+		fmt.Println("No committed offsets")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tPARTITION\tCOMMITTED\tLOG-END\tLAG\tMEMBER")
+	for _, t := range description.Topics {
+		for _, p := range t.Partitions {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\t%s\n", t.Topic, p.Partition, formatOffset(p.Committed), p.LogEnd, formatOffset(p.Lag), p.MemberID)
+		}
+		fmt.Fprintf(w, "%s\ttotal\t\t\t%d\t\n", t.Topic, t.Lag)
+	}
+	_ = w.Flush()
+}
+
+// formatAssignment formats assigned partitions as topic[0,1] other[2]
+func formatAssignment(assignment map[string][]int32) string {
+	if len(assignment) == 0 {
+		return "-"
+	}
+
+	topics := make([]string, 0, len(assignment))
+	for topic := range assignment {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	parts := make([]string, len(topics))
+	for i, topic := range topics {
+		partitions := append([]int32(nil), assignment[topic]...)
+		sort.Slice(partitions, func(a, b int) bool { return partitions[a] < partitions[b] })
+		parts[i] = topic + "[" + joinIDs(partitions) + "]"
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatOffset formats an offset, "-" if unknown
+func formatOffset(offset int64) string {
+	if offset < 0 {
+		return "-"
+	}
+	return strconv.FormatInt(offset, 10)
+}
+
+func printJSON(v interface{}) {
+	output, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		logger.Panic("Failed to encode JSON output", err)
+	}
+	fmt.Println(string(output))
+}
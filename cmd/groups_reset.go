@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/philipparndt/go-logger"
+	"gokcat/config"
+	"gokcat/internal/kafka"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	PlanFormatCSV  = "csv"
+	PlanFormatJSON = "json"
+)
+
+type resetOptions struct {
+	topics     []string
+	toEarliest bool
+	toLatest   bool
+	toOffset   int64
+	toDatetime string
+	shiftBy    int64
+	fromFile   string
+	execute    bool
+	export     string
+	scope      []kafka.TopicPartitions
+	reset      kafka.OffsetReset
+}
+
+var resetOpts = resetOptions{}
+
+var groupsResetOffsetsCmd = &cobra.Command{
+	Use:   "reset-offsets <group>",
+	Short: "Reset the committed offsets of a consumer group",
+	Long: `Reset the committed offsets of a consumer group for whole topics (--topic my-topic)
+or single partitions (--topic my-topic:0,1).
+
+By default only the plan is shown. Use --execute to commit the new offsets.
+The plan can be exported with --export csv|json and applied later with --from-file.
+Offsets can only be reset while the group has no active members.`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if resetOpts.export != "" && resetOpts.export != PlanFormatCSV && resetOpts.export != PlanFormatJSON {
+			return fmt.Errorf("invalid export format %q, valid formats are %s and %s", resetOpts.export, PlanFormatCSV, PlanFormatJSON)
+		}
+
+		scope, err := parseTopicPartitions(resetOpts.topics)
+		if err != nil {
+			return err
+		}
+		resetOpts.scope = scope
+
+		reset, err := parseOffsetReset(cmd)
+		if err != nil {
+			return err
+		}
+		resetOpts.reset = reset
+
+		if len(resetOpts.scope) == 0 {
+			if reset.Kind != kafka.ResetToPlan {
+				return errors.New("you must specify at least one --topic")
+			}
+			resetOpts.scope = planScope(reset.Plan)
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runGroupsResetOffsets(args[0], cfg, resetOpts)
+	},
+}
+
+func init() {
+	groupsCmd.AddCommand(groupsResetOffsetsCmd)
+	flags := groupsResetOffsetsCmd.Flags()
+	flags.StringArrayVar(&resetOpts.topics, "topic", nil, "Topic to reset, optionally with partitions (topic:0,1,2), can be repeated")
+	flags.BoolVar(&resetOpts.toEarliest, "to-earliest", false, "Reset to the oldest available offset")
+	flags.BoolVar(&resetOpts.toLatest, "to-latest", false, "Reset to the end of the partitions")
+	flags.Int64Var(&resetOpts.toOffset, "to-offset", 0, "Reset to this offset")
+	flags.StringVar(&resetOpts.toDatetime, "to-datetime", "", "Reset to the first message at or after this time (milliseconds since epoch or RFC3339)")
+	flags.Int64Var(&resetOpts.shiftBy, "shift-by", 0, "Move the committed offsets by N messages, negative values move backwards")
+	flags.StringVar(&resetOpts.fromFile, "from-file", "", "Reset to the offsets of a plan exported as CSV or JSON")
+	flags.BoolVar(&resetOpts.execute, "execute", false, "Commit the new offsets instead of only showing the plan")
+	flags.StringVar(&resetOpts.export, "export", "", "Print the plan as csv or json")
+	groupsResetOffsetsCmd.MarkFlagsMutuallyExclusive("to-earliest", "to-latest", "to-offset", "to-datetime", "shift-by", "from-file")
+	groupsResetOffsetsCmd.MarkFlagsOneRequired("to-earliest", "to-latest", "to-offset", "to-datetime", "shift-by", "from-file")
+}
+
+func runGroupsResetOffsets(group string, cfg config.Config, opts resetOptions) {
+	client, admin := newClusterAdmin(cfg)
+	defer admin.Close()
+
+	if err := kafka.EnsureGroupInactive(admin, group); err != nil {
+		logger.Panic("Refusing to reset offsets", err)
+	}
+
+	committed, err := kafka.CommittedOffsets(admin, group)
+	if err != nil {
+		logger.Panic("Failed to get committed offsets", err)
+	}
+
+	plan, err := kafka.PlanOffsetReset(client, committed, opts.scope, opts.reset)
+	if err != nil {
+		logger.Panic("Failed to plan offset reset", err)
+	}
+
+	if err := writeOffsetPlan(os.Stdout, plan, opts.export); err != nil {
+		logger.Panic("Failed to write plan", err)
+	}
+
+	if len(plan) == 0 {
+		logger.Info("No partitions to reset")
+		return
+	}
+
+	if !opts.execute {
+		logger.Info("Dry run, use --execute to commit the new offsets")
+		return
+	}
+
+	if err := kafka.CommitOffsets(client, group, plan); err != nil {
+		logger.Panic("Failed to reset offsets", err)
+	}
+	logger.Info(fmt.Sprintf("Reset offsets of %d partitions of consumer group %s", len(plan), group))
+}
+
+// parseOffsetReset builds the reset strategy from the flag that was set
+func parseOffsetReset(cmd *cobra.Command) (kafka.OffsetReset, error) {
+	flags := cmd.Flags()
+	switch {
+	case flags.Changed("to-latest"):
+		return kafka.OffsetReset{Kind: kafka.ResetLatest}, nil
+	case flags.Changed("to-offset"):
+		if resetOpts.toOffset < 0 {
+			return kafka.OffsetReset{}, errors.New("--to-offset must not be negative")
+		}
+		return kafka.OffsetReset{Kind: kafka.ResetToOffset, Offset: resetOpts.toOffset}, nil
+	case flags.Changed("to-datetime"):
+		t, err := kafka.ParseTimestamp(resetOpts.toDatetime)
+		if err != nil {
+			return kafka.OffsetReset{}, err
+		}
+		return kafka.OffsetReset{Kind: kafka.ResetToTime, Time: t}, nil
+	case flags.Changed("shift-by"):
+		return kafka.OffsetReset{Kind: kafka.ResetShiftBy, Shift: resetOpts.shiftBy}, nil
+	case flags.Changed("from-file"):
+		plan, err := readOffsetPlan(resetOpts.fromFile)
+		if err != nil {
+			return kafka.OffsetReset{}, err
+		}
+		return kafka.OffsetReset{Kind: kafka.ResetToPlan, Plan: plan}, nil
+	default:
+		return kafka.OffsetReset{Kind: kafka.ResetEarliest}, nil
+	}
+}
+
+// parseTopicPartitions parses topic or topic:0,1,2 selections
+func parseTopicPartitions(values []string) ([]kafka.TopicPartitions, error) {
+	result := make([]kafka.TopicPartitions, 0, len(values))
+	for _, value := range values {
+		topic, list, hasPartitions := strings.Cut(value, ":")
+		if topic == "" {
+			return nil, fmt.Errorf("invalid topic %q", value)
+		}
+		if slices.ContainsFunc(result, func(s kafka.TopicPartitions) bool { return s.Topic == topic }) {
+			return nil, fmt.Errorf("topic %s is selected more than once", topic)
+		}
+
+		selection := kafka.TopicPartitions{Topic: topic}
+		if hasPartitions {
+			for _, p := range strings.Split(list, ",") {
+				partition, err := parsePartition(strings.TrimSpace(p))
+				if err != nil {
+					return nil, fmt.Errorf("invalid partition %q of topic %s", p, topic)
+				}
+				if slices.Contains(selection.Partitions, partition) {
+					return nil, fmt.Errorf("duplicate partition %d of topic %s", partition, topic)
+				}
+				selection.Partitions = append(selection.Partitions, partition)
+			}
+		}
+		result = append(result, selection)
+	}
+	return result, nil
+}
+
+// parsePartition parses a partition number, which must be between 0 and the maximum int32
+func parsePartition(value string) (int32, error) {
+	partition, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if partition < 0 {
+		return 0, fmt.Errorf("partition %d is negative", partition)
+	}
+	return int32(partition), nil
+}
+
+// planScope selects the topics and partitions of a plan, so that partitions a topic does not have are reported
+func planScope(plan map[string]map[int32]int64) []kafka.TopicPartitions {
+	scope := make([]kafka.TopicPartitions, 0, len(plan))
+	for topic, offsets := range plan {
+		selection := kafka.TopicPartitions{Topic: topic}
+		for partition := range offsets {
+			selection.Partitions = append(selection.Partitions, partition)
+		}
+		slices.Sort(selection.Partitions)
+		scope = append(scope, selection)
+	}
+	slices.SortFunc(scope, func(a, b kafka.TopicPartitions) int { return strings.Compare(a.Topic, b.Topic) })
+	return scope
+}
+
+// readOffsetPlan reads a plan exported as JSON or as CSV (topic,partition,offset)
+func readOffsetPlan(file string) (map[string]map[int32]int64, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan %s: %w", file, err)
+	}
+
+	var entries []kafka.OffsetPlanEntry
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON plan %s: %w", file, err)
+		}
+	} else {
+		entries, err = readCSVPlan(bytes.NewReader(trimmed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV plan %s: %w", file, err)
+		}
+	}
+
+	plan := make(map[string]map[int32]int64)
+	for _, entry := range entries {
+		if entry.Topic == "" {
+			return nil, fmt.Errorf("missing topic for partition %d", entry.Partition)
+		}
+		if entry.Partition < 0 {
+			return nil, fmt.Errorf("invalid partition %d of topic %s", entry.Partition, entry.Topic)
+		}
+		if entry.Offset < 0 {
+			return nil, fmt.Errorf("invalid offset %d for topic %s partition %d", entry.Offset, entry.Topic, entry.Partition)
+		}
+		if plan[entry.Topic] == nil {
+			plan[entry.Topic] = make(map[int32]int64)
+		}
+		if _, ok := plan[entry.Topic][entry.Partition]; ok {
+			return nil, fmt.Errorf("duplicate partition %d of topic %s", entry.Partition, entry.Topic)
+		}
+		plan[entry.Topic][entry.Partition] = entry.Offset
+	}
+	return plan, nil
+}
+
+func readCSVPlan(input io.Reader) ([]kafka.OffsetPlanEntry, error) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]kafka.OffsetPlanEntry, 0, len(records))
+	for i, record := range records {
+		// an optional header line
+		if i == 0 && strings.EqualFold(record[1], "partition") {
+			continue
+		}
+		partition, perr := strconv.ParseInt(record[1], 10, 32)
+		offset, oerr := strconv.ParseInt(record[2], 10, 64)
+		if perr != nil || oerr != nil {
+			return nil, fmt.Errorf("invalid line %d, expected topic,partition,offset", i+1)
+		}
+		entries = append(entries, kafka.OffsetPlanEntry{Topic: record[0], Partition: int32(partition), Offset: offset})
+	}
+	return entries, nil
+}
+
+// writeOffsetPlan writes the plan as a table, or in the given export format
+func writeOffsetPlan(output io.Writer, plan []kafka.OffsetPlanEntry, format string) error {
+	switch format {
+	case PlanFormatJSON:
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(output, string(data))
+		return err
+	case PlanFormatCSV:
+		writer := bufio.NewWriter(output)
+		for _, entry := range plan {
+			fmt.Fprintf(writer, "%s,%d,%d\n", entry.Topic, entry.Partition, entry.Offset)
+		}
+		return writer.Flush()
+	default:
+		w := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TOPIC\tPARTITION\tCURRENT\tNEW")
+		for _, entry := range plan {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\n", entry.Topic, entry.Partition, formatOffset(entry.Current), entry.Offset)
+		}
+		return w.Flush()
+	}
+}
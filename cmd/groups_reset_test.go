@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gokcat/internal/kafka"
+)
+
+func TestParseTopicPartitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []kafka.TopicPartitions
+		wantErr bool
+	}{
+		{"topic", []string{"orders"}, []kafka.TopicPartitions{{Topic: "orders"}}, false},
+		{"partitions", []string{"orders:0, 2", "payments"}, []kafka.TopicPartitions{{Topic: "orders", Partitions: []int32{0, 2}}, {Topic: "payments"}}, false},
+		{"missing topic", []string{":0"}, nil, true},
+		{"empty partition list", []string{"orders:"}, nil, true},
+		{"empty partition", []string{"orders:0,,1"}, nil, true},
+		{"invalid partition", []string{"orders:a"}, nil, true},
+		{"negative partition", []string{"orders:-1"}, nil, true},
+		{"partition out of range", []string{"orders:2147483648"}, nil, true},
+		{"duplicate partition", []string{"orders:1,1"}, nil, true},
+		{"duplicate topic", []string{"orders:0", "orders:1"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTopicPartitions(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTopicPartitions(%q) error = %v, want error %v", tt.values, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseTopicPartitions(%q) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOffsetPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]map[int32]int64
+		wantErr bool
+	}{
+		{"csv", "orders,0,10\norders,1,20\npayments,0,5\n", map[string]map[int32]int64{"orders": {0: 10, 1: 20}, "payments": {0: 5}}, false},
+		{"csv with header", "topic,partition,offset\norders, 0, 10\n", map[string]map[int32]int64{"orders": {0: 10}}, false},
+		{"json", `[{"topic": "orders", "partition": 1, "current": 3, "offset": 20}]`, map[string]map[int32]int64{"orders": {1: 20}}, false},
+		{"empty", "", map[string]map[int32]int64{}, false},
+		{"csv missing column", "orders,0\n", nil, true},
+		{"csv extra column", "orders,0,10,1\n", nil, true},
+		{"csv invalid first line", "orders,x,10\n", nil, true},
+		{"csv invalid offset", "orders,0,10\norders,1,x\n", nil, true},
+		{"csv missing topic", ",0,10\n", nil, true},
+		{"csv negative partition", "orders,-1,10\n", nil, true},
+		{"csv partition out of range", "orders,0,10\norders,2147483648,10\n", nil, true},
+		{"csv negative offset", "orders,0,-2\n", nil, true},
+		{"csv duplicate partition", "orders,0,10\norders,0,20\n", nil, true},
+		{"json malformed", `[{"topic": "orders", "partition": 0`, nil, true},
+		{"json partition out of range", `[{"topic": "orders", "partition": 2147483648, "offset": 1}]`, nil, true},
+		{"json negative partition", `[{"topic": "orders", "partition": -1, "offset": 1}]`, nil, true},
+		{"json duplicate partition", `[{"topic": "orders", "partition": 0, "offset": 1}, {"topic": "orders", "partition": 0, "offset": 2}]`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "plan")
+			if err := os.WriteFile(file, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readOffsetPlan(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readOffsetPlan() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("readOffsetPlan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanScope(t *testing.T) {
+	plan := map[string]map[int32]int64{"payments": {3: 1, 0: 2}, "orders": {1: 5}}
+	want := []kafka.TopicPartitions{{Topic: "orders", Partitions: []int32{1}}, {Topic: "payments", Partitions: []int32{0, 3}}}
+
+	if got := planScope(plan); !reflect.DeepEqual(got, want) {
+		t.Fatalf("planScope() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/philipparndt/go-logger"
+	"gokcat/config"
+	"gokcat/internal/kafka"
+	"gokcat/internal/kafka/schemaRegistry"
+	"gokcat/message"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// produceCmd represents the produce command
+var produceCmd = &cobra.Command{
+	Use:   "produce",
+	Short: "Write JSON records to a Kafka topic",
+	Long: `Write JSON records to a Kafka topic.
+
+The records are read from stdin or a file, either as a JSON array or as newline-delimited JSON,
+in the same shape gokcat writes messages. Output of gokcat can therefore be replayed directly.
+Values and keys are serialized in the Confluent wire format if a schema is selected by flag or
+given by the "schema"/"keySchema" id of the record, otherwise they are written as JSON.
+Values and keys with a "payloadEncoding"/"keyEncoding" (text, escaped-json or base64) are restored as written by gokcat.
+Records with a null or missing key are produced without a key. JSON values are written re-encoded, with the same content
+but not necessarily the same bytes (e.g. whitespace and key order).
+Avro values are read in the form of --avro-rendering, as written by gokcat with the same flag.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if configFile == "" && systemAlias == "" {
+			return errors.New("you must specify a config file or system alias")
+		}
+		if topic == "" {
+			return errors.New("you must specify a topic to produce to")
+		}
+		if !slices.Contains(schemaRegistry.AvroRenderings, produceOpts.avroRendering) {
+			return fmt.Errorf("unknown Avro rendering %q, expected one of %s", produceOpts.avroRendering, strings.Join(schemaRegistry.AvroRenderings, ", "))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runProduce(topic, cfg, produceOpts)
+	},
+}
+
+type schemaSelector struct {
+	id      int
+	subject string
+	version string
+}
+
+type produceOptions struct {
+	file          string
+	valueSchema   schemaSelector
+	keySchema     schemaSelector
+	avroRendering string
+}
+
+var produceOpts = produceOptions{}
+
+func init() {
+	rootCmd.AddCommand(produceCmd)
+	produceCmd.Flags().StringVarP(&topic, "topic", "t", "", "Kafka topic to produce messages to")
+	produceCmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to the configuration file")
+	produceCmd.Flags().StringVarP(&systemAlias, "systemAlias", "s", "", "System alias")
+	produceCmd.Flags().StringVar(&brokers, "brokers", "", "Comma-separated bootstrap brokers, overrides the brokers of the config")
+	produceCmd.Flags().StringVarP(&produceOpts.file, "file", "F", "", "Read records from this file instead of stdin")
+	produceCmd.Flags().IntVar(&produceOpts.valueSchema.id, "value-schema-id", 0, "Serialize values with the schema of this ID")
+	produceCmd.Flags().StringVar(&produceOpts.valueSchema.subject, "value-subject", "", "Serialize values with the schema of this subject")
+	produceCmd.Flags().StringVar(&produceOpts.valueSchema.version, "value-version", "latest", "Version of the value subject")
+	produceCmd.Flags().IntVar(&produceOpts.keySchema.id, "key-schema-id", 0, "Serialize keys with the schema of this ID")
+	produceCmd.Flags().StringVar(&produceOpts.keySchema.subject, "key-subject", "", "Serialize keys with the schema of this subject")
+	produceCmd.Flags().StringVar(&produceOpts.keySchema.version, "key-version", "latest", "Version of the key subject")
+	produceCmd.Flags().StringVar(&produceOpts.avroRendering, "avro-rendering", schemaRegistry.AvroNatural, "JSON form of the Avro values to read: "+strings.Join(schemaRegistry.AvroRenderings, ", "))
+	produceCmd.MarkFlagsMutuallyExclusive("value-schema-id", "value-subject")
+	produceCmd.MarkFlagsMutuallyExclusive("key-schema-id", "key-subject")
+}
+
+// record is the input format, it matches the JSON written by message.Message
+type record struct {
+	Schema    message.SchemaRef `json:"schema"`
+	KeySchema message.SchemaRef `json:"keySchema"`
+	Metadata  struct {
+		Partition   *int32            `json:"partition"`
+		Timestamp   string            `json:"timestamp"`
+		Key         interface{}       `json:"key"`
+		KeyEncoding string            `json:"keyEncoding"`
+		Headers     map[string]string `json:"headers"`
+	} `json:"metadata"`
+	Payload         interface{} `json:"payload"`
+	PayloadEncoding string      `json:"payloadEncoding"`
+}
+
+func runProduce(topic string, cfg config.Config, opts produceOptions) {
+	kConfig := newSaramaConfig(cfg)
+	kConfig.Producer.Return.Successes = true
+	kConfig.Producer.RequiredAcks = sarama.WaitForAll
+	kConfig.Producer.Partitioner = kafka.NewRecordPartitioner
+
+	sr := newSchemaRegistry(cfg)
+	serializer := sr.NewSerializer()
+	serializer.AvroRendering = opts.avroRendering
+
+	valueSchema, err := selectSchema(&serializer, opts.valueSchema)
+	if err != nil {
+		logger.Panic("Failed to load value schema", err)
+	}
+
+	keySchema, err := selectSchema(&serializer, opts.keySchema)
+	if err != nil {
+		logger.Panic("Failed to load key schema", err)
+	}
+
+	var input io.Reader = os.Stdin
+	if opts.file != "" {
+		file, err := os.Open(opts.file)
+		if err != nil {
+			logger.Panic("Failed to open input file", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	client := newClient(cfg, kConfig)
+	defer client.Close()
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		logger.Panic("Failed to create producer from client", err)
+	}
+	defer producer.Close()
+
+	ctr := 0
+	err = readRecords(input, func(rec record) error {
+		value, err := encodeData(&serializer, valueSchema, rec.Schema.Id, rec.Payload, rec.PayloadEncoding, false)
+		if err != nil {
+			return fmt.Errorf("failed to encode value of record %d: %w", ctr, err)
+		}
+
+		key, err := encodeData(&serializer, keySchema, rec.KeySchema.Id, rec.Metadata.Key, rec.Metadata.KeyEncoding, true)
+		if err != nil {
+			return fmt.Errorf("failed to encode key of record %d: %w", ctr, err)
+		}
+
+		msg := &sarama.ProducerMessage{
+			Topic: topic,
+			Key:   encoderOf(key),
+			Value: encoderOf(value),
+		}
+
+		if rec.Metadata.Partition != nil {
+			msg.Partition = *rec.Metadata.Partition
+			msg.Metadata = kafka.ExplicitPartition{}
+		}
+
+		if rec.Metadata.Timestamp != "" {
+			timestamp, err := time.Parse(time.RFC3339, rec.Metadata.Timestamp)
+			if err != nil {
+				return fmt.Errorf("invalid timestamp of record %d: %w", ctr, err)
+			}
+			msg.Timestamp = timestamp
+		}
+
+		for name, value := range rec.Metadata.Headers {
+			msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(name), Value: []byte(value)})
+		}
+
+		partition, offset, err := producer.SendMessage(msg)
+		if err != nil {
+			return fmt.Errorf("failed to produce record %d: %w", ctr, err)
+		}
+		logger.Debug("Produced record", strconv.Itoa(ctr), "to partition", strconv.Itoa(int(partition)), "at offset", strconv.Itoa(int(offset)))
+
+		ctr++
+		return nil
+	})
+	if err != nil {
+		logger.Panic("Failed to produce records", err)
+	}
+
+	logger.Info(fmt.Sprintf("Produced %d records to topic %s", ctr, topic))
+}
+
+// selectSchema loads the schema selected by ID or subject, nil if none is selected
+func selectSchema(serializer *schemaRegistry.Serializer, selector schemaSelector) (*schemaRegistry.Schema, error) {
+	switch {
+	case selector.id > 0:
+		return serializer.SchemaByID(selector.id)
+	case selector.subject != "":
+		return serializer.SchemaBySubject(selector.subject, selector.version)
+	default:
+		return nil, nil
+	}
+}
+
+// encodeData serializes data with the selected schema or the schema ID of the record.
+// Data with an encoding (see message.EncodingBase64) is restored as written by gokcat.
+// Without a schema, data is written as JSON, except for key strings which are written as is. A null key is no key.
+func encodeData(serializer *schemaRegistry.Serializer, selected *schemaRegistry.Schema, recordSchemaID int, data interface{}, encoding string, isKey bool) ([]byte, error) {
+	if isKey && data == nil {
+		return nil, nil
+	}
+	if encoding != "" && data != nil {
+		return decodeEncoding(data, encoding)
+	}
+
+	schema := selected
+	if schema == nil && recordSchemaID > 0 {
+		s, err := serializer.SchemaByID(recordSchemaID)
+		if err != nil {
+			return nil, err
+		}
+		schema = s
+	}
+
+	if schema != nil {
+		return serializer.Serialize(schema, data)
+	}
+
+	switch value := data.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if isKey {
+			return []byte(value), nil
+		}
+	}
+	return json.Marshal(data)
+}
+
+// decodeEncoding restores data written by gokcat with the given encoding
+func decodeEncoding(data interface{}, encoding string) ([]byte, error) {
+	switch encoding {
+	case message.EncodingText:
+		value, ok := data.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", data)
+		}
+		return []byte(value), nil
+	case message.EncodingBase64:
+		value, ok := data.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a base64 string, got %T", data)
+		}
+		return base64.StdEncoding.DecodeString(value)
+	case message.EncodingEscapedJSON:
+		value, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		quoted := strconv.Quote(string(value))
+		return []byte(quoted[1 : len(quoted)-1]), nil
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+}
+
+// encoderOf keeps nil data as nil, so that records without key or value are produced as such
+func encoderOf(data []byte) sarama.Encoder {
+	if data == nil {
+		return nil
+	}
+	return sarama.ByteEncoder(data)
+}
+
+// readRecords reads a JSON array of records or a stream of records (e.g. newline-delimited JSON)
+func readRecords(input io.Reader, handle func(rec record) error) error {
+	reader := bufio.NewReader(input)
+
+	isArray := false
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			_, _ = reader.ReadByte()
+			continue
+		}
+		isArray = b[0] == '['
+		break
+	}
+
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	if isArray {
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	}
+
+	for decoder.More() {
+		var rec record
+		if err := decoder.Decode(&rec); err != nil {
+			return fmt.Errorf("failed to read record: %w", err)
+		}
+		if err := handle(rec); err != nil {
+			return err
+		}
+	}
+
+	if isArray {
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
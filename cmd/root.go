@@ -2,9 +2,16 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"github.com/philipparndt/go-logger"
 	"gokcat/config"
+	"gokcat/internal/kafka"
+	"gokcat/internal/kafka/schemaRegistry"
+	"gokcat/message"
+	"io"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -21,22 +28,73 @@ var rootCmd = &cobra.Command{
 		if topic == "" {
 			return errors.New("you must specify a topic to cat")
 		}
-		return nil
-	},
-	Run: func(cmd *cobra.Command, args []string) {
-		if systemAlias != "" {
-			configFile = "~/.config/gokcat/" + systemAlias + "/config.json"
+		if !slices.Contains(message.OutputModes, output) {
+			return fmt.Errorf("unknown output mode %q, expected one of %s", output, strings.Join(message.OutputModes, ", "))
 		}
-
-		cfg, err := config.LoadConfig(configFile)
-		if err != nil {
-			logger.Panic("Failed to load config", configFile, ",", err)
+		if !slices.Contains(schemaRegistry.AvroRenderings, avroRendering) {
+			return fmt.Errorf("unknown Avro rendering %q, expected one of %s", avroRendering, strings.Join(schemaRegistry.AvroRenderings, ", "))
+		}
+		if format != "" {
+			if _, err := message.NewTemplateWriter(format, io.Discard); err != nil {
+				return err
+			}
 		}
+		if !slices.Contains(commitModes, groupOpts.commit) {
+			return fmt.Errorf("unknown commit mode %q, expected one of %s", groupOpts.commit, strings.Join(commitModes, ", "))
+		}
+		if _, ok := initialOffsets[groupOpts.initialOffset]; !ok {
+			return fmt.Errorf("unknown initial offset %q, expected oldest or newest", groupOpts.initialOffset)
+		}
+		if filter != "" {
+			f, err := message.NewFilter(filter)
+			if err != nil {
+				return err
+			}
+			messageFilter = f
+		}
+		if err := parseStartPosition(cmd); err != nil {
+			return err
+		}
+		return parseLimits(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
 
-		runCat(topic, cfg, follow)
+		runCat(topic, cfg, catOptions{
+			follow:     follow,
+			partitions: partitions,
+			start:      startPosition,
+			limits:     limits,
+			output:     output,
+			format:     format,
+			filter:     messageFilter,
+			validate:   validate,
+			rendering:  avroRendering,
+			group:      groupOpts,
+			schemas:    schemaFiles,
+		})
 	},
 }
 
+// loadConfig loads the configuration file, or the one of the system alias if given. --brokers replaces the configured brokers.
+func loadConfig() config.Config {
+	if systemAlias != "" {
+		configFile = "~/.config/gokcat/" + systemAlias + "/config.json"
+	}
+
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		logger.Panic("Failed to load config", configFile, ",", err)
+	}
+
+	if brokers != "" {
+		cfg.Brokers = config.ParseBrokers(brokers)
+		cfg.Broker = ""
+	}
+
+	return cfg
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -49,11 +107,112 @@ func Execute() {
 var topic string
 var configFile string
 var systemAlias string
+var brokers string
 var follow bool
+var partitions []int32
+var startOffset int64
+var tailCount int64
+var fromTimestamp string
+var startPosition kafka.StartPosition
+var untilOffset int64
+var untilTimestamp string
+var count int
+var limits kafka.Limits
+var output string
+var format string
+var filter string
+var messageFilter *message.Filter
+var validate bool
+var avroRendering string
+var offline bool
+var groupOpts groupOptions
+var schemaFiles schemaFileFlags
 
 func init() {
 	rootCmd.Flags().StringVarP(&topic, "topic", "t", "", "Kafka topic to consume messages from")
 	rootCmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to the configuration file")
 	rootCmd.Flags().StringVarP(&systemAlias, "systemAlias", "s", "", "System alias")
+	rootCmd.Flags().StringVar(&brokers, "brokers", "", "Comma-separated bootstrap brokers, overrides the brokers of the config")
 	rootCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Follow the topic (like tail -f)")
+	rootCmd.Flags().Int32SliceVarP(&partitions, "partition", "p", nil, "Partitions to consume, can be repeated or comma separated (default: all)")
+	rootCmd.Flags().Int64VarP(&startOffset, "offset", "o", 0, "Start at this absolute offset in each partition")
+	rootCmd.Flags().Int64VarP(&tailCount, "tail", "n", 0, "Start with the last N messages of each partition")
+	rootCmd.Flags().StringVar(&fromTimestamp, "from-timestamp", "", "Start at the first message at or after this time (milliseconds since epoch or RFC3339)")
+	rootCmd.MarkFlagsMutuallyExclusive("offset", "tail", "from-timestamp")
+	rootCmd.Flags().Int64Var(&untilOffset, "until-offset", -1, "Stop each partition after this offset")
+	rootCmd.Flags().StringVar(&untilTimestamp, "until-timestamp", "", "Stop each partition before the first message at or after this time (milliseconds since epoch or RFC3339)")
+	rootCmd.Flags().IntVar(&count, "count", 0, "Stop after this number of output messages in total")
+	rootCmd.Flags().StringVar(&output, "output", message.OutputJSONArray, "Output mode: "+strings.Join(message.OutputModes, ", "))
+	rootCmd.Flags().StringVar(&avroRendering, "avro-rendering", schemaRegistry.AvroNatural, "JSON form of Avro values: "+strings.Join(schemaRegistry.AvroRenderings, ", "))
+	rootCmd.Flags().StringVar(&format, "format", "", "Render each message with a Go text/template, e.g. '{{.Partition}} {{.Offset}} {{.Key}} {{json .Payload}}'")
+	rootCmd.MarkFlagsMutuallyExclusive("output", "format")
+	rootCmd.Flags().BoolVar(&validate, "validate", false, "Validate JSON Schema framed payloads against their schema and report mismatches in the output")
+	rootCmd.Flags().BoolVar(&offline, "offline", false, "Decode with cached schemas only, without contacting the Schema Registry")
+	rootCmd.Flags().StringArrayVar(&schemaFiles.byID, "schema-file", nil, "Use a local Avro schema file for a schema ID as ID=path, can be repeated")
+	rootCmd.Flags().StringVar(&schemaFiles.value, "value-schema-file", "", "Decode all values with this Avro schema file, plain Avro or framed")
+	rootCmd.Flags().StringVar(&schemaFiles.key, "key-schema-file", "", "Decode all keys with this Avro schema file, plain Avro or framed")
+	rootCmd.Flags().StringVar(&schemaFiles.readerSubject, "reader-subject", "", "Read all Avro values as the schema of this subject, using Avro schema resolution")
+	rootCmd.Flags().StringVar(&schemaFiles.readerVersion, "reader-version", "latest", "Version of the reader subject")
+	rootCmd.Flags().StringVar(&schemaFiles.readerFile, "reader-schema-file", "", "Read all Avro values as the schema of this file, using Avro schema resolution")
+	rootCmd.MarkFlagsMutuallyExclusive("reader-subject", "reader-schema-file")
+	rootCmd.Flags().StringVarP(&groupOpts.id, "group", "g", "", "Consume as a member of this consumer group, starting at its committed offsets")
+	rootCmd.Flags().StringVar(&groupOpts.commit, "commit", CommitAfterOutput, "When to commit offsets in a consumer group: "+strings.Join(commitModes, ", "))
+	rootCmd.Flags().StringVar(&groupOpts.initialOffset, "group-initial-offset", "oldest", "Where to start in a consumer group without committed offsets: oldest, newest")
+	for _, flag := range []string{"partition", "offset", "tail", "from-timestamp", "until-offset", "until-timestamp"} {
+		rootCmd.MarkFlagsMutuallyExclusive("group", flag)
+	}
+	rootCmd.Flags().StringVar(&filter, "filter", "", "Only output messages matching this jq expression, e.g. '.payload.status == \"FAILED\"'")
+}
+
+// parseStartPosition converts the start offset flags into a kafka.StartPosition
+func parseStartPosition(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	switch {
+	case flags.Changed("offset"):
+		if startOffset < 0 {
+			return errors.New("offset must not be negative, use --tail to start relative to the end")
+		}
+		startPosition = kafka.StartPosition{Kind: kafka.StartOffset, Offset: startOffset}
+	case flags.Changed("tail"):
+		if tailCount < 0 {
+			return errors.New("tail must not be negative")
+		}
+		startPosition = kafka.StartPosition{Kind: kafka.StartTail, Tail: tailCount}
+	case flags.Changed("from-timestamp"):
+		t, err := kafka.ParseTimestamp(fromTimestamp)
+		if err != nil {
+			return err
+		}
+		startPosition = kafka.StartPosition{Kind: kafka.StartTimestamp, Time: t}
+	default:
+		startPosition = kafka.StartPosition{Kind: kafka.StartOldest}
+	}
+	return nil
+}
+
+// parseLimits converts the stop condition flags into kafka.Limits
+func parseLimits(cmd *cobra.Command) error {
+	limits = kafka.NoLimits()
+
+	if cmd.Flags().Changed("until-offset") {
+		if untilOffset < 0 {
+			return errors.New("until-offset must not be negative")
+		}
+		limits.UntilOffset = untilOffset
+	}
+
+	if untilTimestamp != "" {
+		t, err := kafka.ParseTimestamp(untilTimestamp)
+		if err != nil {
+			return err
+		}
+		limits.UntilTime = t
+	}
+
+	if count < 0 {
+		return errors.New("count must not be negative")
+	}
+	limits.Count = count
+
+	return nil
 }
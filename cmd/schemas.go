@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/philipparndt/go-logger"
+	"gokcat/config"
+	"gokcat/internal/kafka/schemaRegistry"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// schemasCmd represents the schemas command
+var schemasCmd = &cobra.Command{
+	Use:   "schemas",
+	Short: "Inspect the Schema Registry",
+	Long:  `List subjects and versions, show schemas and compatibility levels of the configured Schema Registry.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configFile == "" && systemAlias == "" {
+			return errors.New("you must specify a config file or system alias")
+		}
+		return nil
+	},
+}
+
+var schemasSubjectsCmd = &cobra.Command{
+	Use:   "subjects",
+	Short: "List all subjects, or the subjects using a schema ID",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runSchemasSubjects(cfg, schemaID)
+	},
+}
+
+var schemasVersionsCmd = &cobra.Command{
+	Use:   "versions <subject>",
+	Short: "List the versions of a subject",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runSchemasVersions(args[0], cfg)
+	},
+}
+
+var schemasGetCmd = &cobra.Command{
+	Use:   "get [<subject> [<version>]]",
+	Short: "Show a schema by subject and version or by ID",
+	Long: `Show a schema by subject and version (default latest), or by ID with --id.
+Avro and JSON schemas are pretty-printed.`,
+	Args: cobra.RangeArgs(0, 2),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if (schemaID > 0) == (len(args) > 0) {
+			return errors.New("you must specify either a subject or --id")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		if schemaID > 0 {
+			runSchemasGetByID(cfg, schemaID)
+			return
+		}
+
+		version := "latest"
+		if len(args) > 1 {
+			version = args[1]
+		}
+		runSchemasGet(cfg, args[0], version)
+	},
+}
+
+var schemasCompatibilityCmd = &cobra.Command{
+	Use:   "compatibility [<subject>]",
+	Short: "Show the global or the subject compatibility level",
+	Args:  cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		subject := ""
+		if len(args) > 0 {
+			subject = args[0]
+		}
+		runSchemasCompatibility(cfg, subject)
+	},
+}
+
+var schemaID int
+
+func init() {
+	rootCmd.AddCommand(schemasCmd)
+	schemasCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to the configuration file")
+	schemasCmd.PersistentFlags().StringVarP(&systemAlias, "systemAlias", "s", "", "System alias")
+
+	schemasCmd.AddCommand(schemasSubjectsCmd)
+	schemasSubjectsCmd.Flags().IntVar(&schemaID, "id", 0, "Only list the subjects and versions using this schema ID")
+
+	schemasCmd.AddCommand(schemasVersionsCmd)
+
+	schemasCmd.AddCommand(schemasGetCmd)
+	schemasGetCmd.Flags().IntVar(&schemaID, "id", 0, "Schema ID")
+	schemasGetCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the registry response in JSON format")
+
+	schemasCmd.AddCommand(schemasCompatibilityCmd)
+}
+
+func runSchemasSubjects(cfg config.Config, id int) {
+	sr := newSchemaRegistry(cfg)
+
+	if id > 0 {
+		usages, err := sr.GetSubjectVersionsByID(id)
+		if err != nil {
+			logger.Panic("Failed to get subjects of schema", strconv.Itoa(id), err)
+		}
+		for _, usage := range usages {
+			fmt.Printf("%s\t%d\n", usage.Subject, usage.Version)
+		}
+		return
+	}
+
+	subjects, err := sr.GetSubjects()
+	if err != nil {
+		logger.Panic("Failed to get subjects", err)
+	}
+
+	sort.Strings(subjects)
+	for _, subject := range subjects {
+		fmt.Println(subject)
+	}
+}
+
+func runSchemasVersions(subject string, cfg config.Config) {
+	sr := newSchemaRegistry(cfg)
+
+	versions, err := sr.GetVersions(subject)
+	if err != nil {
+		logger.Panic("Failed to get versions of subject", subject, err)
+	}
+
+	for _, version := range versions {
+		fmt.Println(version)
+	}
+}
+
+func runSchemasGet(cfg config.Config, subject string, version string) {
+	sr := newSchemaRegistry(cfg)
+
+	resp, err := sr.GetSchemaBySubjectVersion(subject, version)
+	if err != nil {
+		logger.Panic("Failed to get schema of subject", subject, "version", version, err)
+	}
+
+	if jsonOutput {
+		printJSON(resp)
+		return
+	}
+
+	fmt.Println("Subject:", resp.Subject)
+	fmt.Println("Version:", resp.Version)
+	printSchema(resp.ID, resp.SchemaType, resp.References, resp.Schema)
+}
+
+func runSchemasGetByID(cfg config.Config, id int) {
+	sr := newSchemaRegistry(cfg)
+
+	resp, err := sr.GetSchemaByID(id)
+	if err != nil {
+		logger.Panic("Failed to get schema", strconv.Itoa(id), err)
+	}
+
+	if jsonOutput {
+		resp.ID = id
+		printJSON(resp)
+		return
+	}
+
+	printSchema(id, resp.SchemaType, resp.References, resp.Schema)
+}
+
+func runSchemasCompatibility(cfg config.Config, subject string) {
+	sr := newSchemaRegistry(cfg)
+
+	level, err := sr.GetCompatibility(subject)
+	if err != nil {
+		logger.Panic("Failed to get compatibility level", err)
+	}
+
+	fmt.Println(level)
+}
+
+// printSchema prints the schema metadata followed by the schema, JSON based schemas are indented
+func printSchema(id int, schemaType string, references []schemaRegistry.Reference, schema string) {
+	if schemaType == "" {
+		schemaType = schemaRegistry.TypeAvro
+	}
+
+	fmt.Println("ID:", id)
+	fmt.Println("Type:", schemaType)
+	for _, ref := range references {
+		fmt.Printf("Reference: %s -> %s version %d\n", ref.Name, ref.Subject, ref.Version)
+	}
+	fmt.Println()
+
+	if schemaType != schemaRegistry.TypeProtobuf {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(schema), "", "  "); err == nil {
+			fmt.Println(indented.String())
+			return
+		}
+	}
+	fmt.Println(schema)
+}
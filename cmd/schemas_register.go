@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/philipparndt/go-logger"
+	"gokcat/config"
+	"gokcat/internal/kafka/schemaRegistry"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type schemaFileOptions struct {
+	file       string
+	schemaType string
+	references []string
+	version    string
+}
+
+var schemaFileOpts = schemaFileOptions{}
+
+var schemasRegisterCmd = &cobra.Command{
+	Use:   "register <subject>",
+	Short: "Register a schema under a subject",
+	Long: `Register the schema of a file under a subject and print its ID.
+The schema type is PROTOBUF for .proto files, JSON for .json files and AVRO otherwise, unless --type is given.
+References are given as name=subject:version, the version can be latest.`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateSchemaType(schemaFileOpts.schemaType)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runSchemasRegister(args[0], cfg, schemaFileOpts)
+	},
+}
+
+var schemasCheckCmd = &cobra.Command{
+	Use:   "check <subject>",
+	Short: "Check a schema for compatibility with a subject",
+	Long: `Check the schema of a file for compatibility with a version of the subject (default latest),
+using the compatibility level of the subject. Exits with status 1 and prints the reasons if it is incompatible.`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateSchemaType(schemaFileOpts.schemaType)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runSchemasCheck(args[0], cfg, schemaFileOpts)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{schemasRegisterCmd, schemasCheckCmd} {
+		schemasCmd.AddCommand(cmd)
+		cmd.Flags().StringVarP(&schemaFileOpts.file, "file", "F", "", "Schema file (.avsc, .proto or .json)")
+		cmd.Flags().StringVar(&schemaFileOpts.schemaType, "type", "", "Schema type: AVRO, PROTOBUF or JSON (default by file extension)")
+		cmd.Flags().StringArrayVar(&schemaFileOpts.references, "reference", nil, "Schema reference as name=subject:version, can be repeated")
+		_ = cmd.MarkFlagRequired("file")
+	}
+	schemasCheckCmd.Flags().StringVar(&schemaFileOpts.version, "version", "latest", "Version of the subject to check against")
+}
+
+func runSchemasRegister(subject string, cfg config.Config, opts schemaFileOptions) {
+	sr := newSchemaRegistry(cfg)
+
+	request, err := readSchemaRequest(&sr, opts)
+	if err != nil {
+		logger.Panic("Failed to read schema", err)
+	}
+
+	id, err := sr.RegisterSchema(subject, request)
+	if err != nil {
+		logger.Panic("Failed to register schema for subject", subject, err)
+	}
+
+	logger.Info("Registered schema for subject", subject, "with ID", strconv.Itoa(id))
+	fmt.Println(id)
+}
+
+func runSchemasCheck(subject string, cfg config.Config, opts schemaFileOptions) {
+	sr := newSchemaRegistry(cfg)
+
+	request, err := readSchemaRequest(&sr, opts)
+	if err != nil {
+		logger.Panic("Failed to read schema", err)
+	}
+
+	result, err := sr.CheckCompatibility(subject, opts.version, request)
+	if err != nil {
+		logger.Panic("Failed to check compatibility with subject", subject, err)
+	}
+
+	for _, message := range result.Messages {
+		fmt.Println(message)
+	}
+
+	if !result.IsCompatible {
+		logger.Error("Schema is incompatible with subject", subject, "version", opts.version)
+		os.Exit(1)
+	}
+	logger.Info("Schema is compatible with subject", subject, "version", opts.version)
+}
+
+func validateSchemaType(schemaType string) error {
+	switch strings.ToUpper(schemaType) {
+	case "", schemaRegistry.TypeAvro, schemaRegistry.TypeProtobuf, schemaRegistry.TypeJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid schema type %q, valid types are %s, %s and %s", schemaType,
+			schemaRegistry.TypeAvro, schemaRegistry.TypeProtobuf, schemaRegistry.TypeJSON)
+	}
+}
+
+// readSchemaRequest reads the schema file and resolves its references
+func readSchemaRequest(sr *schemaRegistry.Client, opts schemaFileOptions) (schemaRegistry.SchemaRequest, error) {
+	data, err := os.ReadFile(opts.file)
+	if err != nil {
+		return schemaRegistry.SchemaRequest{}, err
+	}
+
+	schemaType := strings.ToUpper(opts.schemaType)
+	if schemaType == "" {
+		schemaType = schemaTypeOf(opts.file)
+	}
+
+	request := schemaRegistry.SchemaRequest{Schema: string(data)}
+	// Avro is the default of the registry and sent without a type
+	if schemaType != "" && schemaType != schemaRegistry.TypeAvro {
+		request.SchemaType = schemaType
+	}
+
+	for _, value := range opts.references {
+		ref, err := parseReference(sr, value)
+		if err != nil {
+			return request, err
+		}
+		request.References = append(request.References, ref)
+	}
+	return request, nil
+}
+
+// schemaTypeOf derives the schema type from the file extension, Avro files usually end with .avsc
+func schemaTypeOf(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".proto":
+		return schemaRegistry.TypeProtobuf
+	case ".json":
+		return schemaRegistry.TypeJSON
+	default:
+		return schemaRegistry.TypeAvro
+	}
+}
+
+// parseReference parses name=subject:version, a version "latest" is resolved to its number
+func parseReference(sr *schemaRegistry.Client, value string) (schemaRegistry.Reference, error) {
+	name, target, ok := strings.Cut(value, "=")
+	subject, version, hasVersion := strings.Cut(target, ":")
+	if !ok || name == "" || subject == "" {
+		return schemaRegistry.Reference{}, fmt.Errorf("invalid reference %q, expected name=subject:version", value)
+	}
+
+	if !hasVersion || version == "latest" {
+		resp, err := sr.GetSchemaBySubjectVersion(subject, "latest")
+		if err != nil {
+			return schemaRegistry.Reference{}, fmt.Errorf("failed to resolve reference %s: %w", name, err)
+		}
+		return schemaRegistry.Reference{Name: name, Subject: subject, Version: resp.Version}, nil
+	}
+
+	n, err := strconv.Atoi(version)
+	if err != nil {
+		return schemaRegistry.Reference{}, fmt.Errorf("invalid version %q of reference %s", version, name)
+	}
+	return schemaRegistry.Reference{Name: name, Subject: subject, Version: n}, nil
+}
@@ -3,10 +3,8 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"github.com/IBM/sarama"
 	"github.com/philipparndt/go-logger"
 	"gokcat/config"
-	"gokcat/internal/kafka"
 	"sort"
 
 	"github.com/spf13/cobra"
@@ -17,21 +15,14 @@ var topicsCmd = &cobra.Command{
 	Use:   "topics",
 	Short: "List all Kafka topics",
 	Long:  `List all Kafka topics available on the configured Kafka cluster.`,
-	PreRunE: func(cmd *cobra.Command, args []string) error {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if configFile == "" && systemAlias == "" {
 			return errors.New("you must specify a config file or system alias")
 		}
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		if systemAlias != "" {
-			configFile = "~/.config/gokcat/" + systemAlias + "/config.json"
-		}
-
-		cfg, err := config.LoadConfig(configFile)
-		if err != nil {
-			logger.Panic("Failed to load config", configFile, ",", err)
-		}
+		cfg := loadConfig()
 
 		runTopics(cfg)
 	},
@@ -40,24 +31,15 @@ var topicsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(topicsCmd)
 	topicsCmd.Flags().StringVarP(&topic, "topic", "t", "", "Kafka topic to consume messages from")
-	topicsCmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to the configuration file")
-	topicsCmd.Flags().StringVarP(&systemAlias, "systemAlias", "s", "", "System alias")
+	topicsCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to the configuration file")
+	topicsCmd.PersistentFlags().StringVarP(&systemAlias, "systemAlias", "s", "", "System alias")
+	topicsCmd.PersistentFlags().StringVar(&brokers, "brokers", "", "Comma-separated bootstrap brokers, overrides the brokers of the config")
 }
 
 func runTopics(cfg config.Config) {
-	tlsConfig, err := kafka.NewTLSConfig(cfg.Certs.ClientCert, cfg.Certs.ClientKey, cfg.Certs.Ca, cfg.Certs.Insecure)
-	if err != nil {
-		logger.Panic("Failed to create TLS config", err)
-	}
-
-	kConfig := sarama.NewConfig()
-	kConfig.Net.TLS.Enable = true
-	kConfig.Net.TLS.Config = tlsConfig
+	kConfig := newSaramaConfig(cfg)
 
-	client, err := sarama.NewClient([]string{cfg.Broker}, kConfig)
-	if err != nil {
-		logger.Panic("Failed to create client", err)
-	}
+	client := newClient(cfg, kConfig)
 	defer client.Close()
 
 	// Get list of topics
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/philipparndt/go-logger"
+	"gokcat/config"
+	"gokcat/internal/kafka"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type topicAdminOptions struct {
+	partitions        int32
+	totalPartitions   int32
+	replicationFactor int16
+	configs           []string
+	resets            []string
+	yes               bool
+}
+
+var topicAdminOpts = topicAdminOptions{}
+
+var topicsCreateCmd = &cobra.Command{
+	Use:   "create <topic>",
+	Short: "Create a topic",
+	Long: `Create a topic. The number of partitions and the replication factor default to the defaults of the brokers
+(num.partitions and default.replication.factor).`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		_, err := parseConfigEntries(topicAdminOpts.configs)
+		return err
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runTopicsCreate(args[0], cfg, topicAdminOpts)
+	},
+}
+
+var topicsDeleteCmd = &cobra.Command{
+	Use:   "delete <topic>",
+	Short: "Delete a topic",
+	Long:  `Delete a topic. You are asked for confirmation unless --yes is given.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runTopicsDelete(args[0], cfg, topicAdminOpts)
+	},
+}
+
+var topicsAddPartitionsCmd = &cobra.Command{
+	Use:   "add-partitions <topic>",
+	Short: "Increase the number of partitions of a topic",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runTopicsAddPartitions(args[0], cfg, topicAdminOpts)
+	},
+}
+
+var topicsAlterConfigCmd = &cobra.Command{
+	Use:   "alter-config <topic>",
+	Short: "Set or reset configuration entries of a topic",
+	Long: `Set or reset configuration entries of a topic, e.g. retention.ms or cleanup.policy.
+Entries that are not given are left unchanged. Reset entries fall back to the broker default.`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if len(topicAdminOpts.configs) == 0 && len(topicAdminOpts.resets) == 0 {
+			return errors.New("you must specify at least one entry to set or reset")
+		}
+		_, err := parseConfigEntries(topicAdminOpts.configs)
+		return err
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runTopicsAlterConfig(args[0], cfg, topicAdminOpts)
+	},
+}
+
+func init() {
+	topicsCmd.AddCommand(topicsCreateCmd)
+	topicsCreateCmd.Flags().Int32Var(&topicAdminOpts.partitions, "partitions", -1, "Number of partitions, -1 for the broker default")
+	topicsCreateCmd.Flags().Int16Var(&topicAdminOpts.replicationFactor, "replication-factor", -1, "Replication factor, -1 for the broker default")
+	topicsCreateCmd.Flags().StringArrayVar(&topicAdminOpts.configs, "set", nil, "Topic configuration entry as key=value, can be repeated")
+
+	topicsCmd.AddCommand(topicsDeleteCmd)
+	topicsDeleteCmd.Flags().BoolVarP(&topicAdminOpts.yes, "yes", "y", false, "Delete without asking for confirmation")
+
+	topicsCmd.AddCommand(topicsAddPartitionsCmd)
+	topicsAddPartitionsCmd.Flags().Int32Var(&topicAdminOpts.totalPartitions, "partitions", 0, "New total number of partitions")
+	_ = topicsAddPartitionsCmd.MarkFlagRequired("partitions")
+
+	topicsCmd.AddCommand(topicsAlterConfigCmd)
+	topicsAlterConfigCmd.Flags().StringArrayVar(&topicAdminOpts.configs, "set", nil, "Configuration entry to set as key=value, can be repeated")
+	topicsAlterConfigCmd.Flags().StringArrayVar(&topicAdminOpts.resets, "reset", nil, "Configuration entry to reset to its default, can be repeated")
+}
+
+func runTopicsCreate(topic string, cfg config.Config, opts topicAdminOptions) {
+	entries, _ := parseConfigEntries(opts.configs)
+
+	client, admin := newClusterAdmin(cfg)
+	defer admin.Close()
+
+	// Brokers before Kafka 2.4 do not accept -1 for the broker defaults
+	if (opts.partitions < 0 || opts.replicationFactor < 0) && !client.Config().Version.IsAtLeast(sarama.V2_4_0_0) {
+		partitions, replicationFactor, err := kafka.TopicDefaults(admin)
+		if err != nil {
+			logger.Panic("Failed to get the topic defaults of the brokers", err)
+		}
+		if opts.partitions < 0 {
+			opts.partitions = partitions
+		}
+		if opts.replicationFactor < 0 {
+			opts.replicationFactor = replicationFactor
+		}
+	}
+
+	err := admin.CreateTopic(topic, &sarama.TopicDetail{
+		NumPartitions:     opts.partitions,
+		ReplicationFactor: opts.replicationFactor,
+		ConfigEntries:     entries,
+	}, false)
+	if err != nil {
+		logger.Panic("Failed to create topic", topic, err)
+	}
+
+	logger.Info(fmt.Sprintf("Created topic %s with %s partitions and replication factor %s", topic,
+		orBrokerDefault(int64(opts.partitions)), orBrokerDefault(int64(opts.replicationFactor))))
+}
+
+// orBrokerDefault formats a topic setting, -1 stands for the broker default
+func orBrokerDefault(value int64) string {
+	if value < 0 {
+		return "the broker default"
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+func runTopicsDelete(topic string, cfg config.Config, opts topicAdminOptions) {
+	if !opts.yes && !confirm(fmt.Sprintf("Delete topic %s?", topic)) {
+		logger.Info("Aborted, topic", topic, "was not deleted")
+		return
+	}
+
+	_, admin := newClusterAdmin(cfg)
+	defer admin.Close()
+
+	if err := admin.DeleteTopic(topic); err != nil {
+		logger.Panic("Failed to delete topic", topic, err)
+	}
+
+	logger.Info("Deleted topic", topic)
+}
+
+func runTopicsAddPartitions(topic string, cfg config.Config, opts topicAdminOptions) {
+	client, admin := newClusterAdmin(cfg)
+	defer admin.Close()
+
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		logger.Panic("Failed to get partitions of topic", topic, err)
+	}
+
+	if opts.totalPartitions <= int32(len(partitions)) {
+		logger.Panic(fmt.Sprintf("Topic %s already has %d partitions, the new number must be larger", topic, len(partitions)))
+	}
+
+	if err := admin.CreatePartitions(topic, opts.totalPartitions, nil, false); err != nil {
+		logger.Panic("Failed to add partitions to topic", topic, err)
+	}
+
+	logger.Info(fmt.Sprintf("Increased partitions of topic %s from %d to %d", topic, len(partitions), opts.totalPartitions))
+}
+
+func runTopicsAlterConfig(topic string, cfg config.Config, opts topicAdminOptions) {
+	values, _ := parseConfigEntries(opts.configs)
+
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(values)+len(opts.resets))
+	for name, value := range values {
+		entries[name] = sarama.IncrementalAlterConfigsEntry{
+			Operation: sarama.IncrementalAlterConfigsOperationSet,
+			Value:     value,
+		}
+	}
+	for _, name := range opts.resets {
+		entries[name] = sarama.IncrementalAlterConfigsEntry{
+			Operation: sarama.IncrementalAlterConfigsOperationDelete,
+		}
+	}
+
+	_, admin := newClusterAdmin(cfg)
+	defer admin.Close()
+
+	if err := admin.IncrementalAlterConfig(sarama.TopicResource, topic, entries, false); err != nil {
+		logger.Panic("Failed to alter config of topic", topic, err)
+	}
+
+	for name, value := range values {
+		logger.Info("Set", name, "to", *value)
+	}
+	for _, name := range opts.resets {
+		logger.Info("Reset", name)
+	}
+}
+
+// parseConfigEntries parses key=value pairs. Values may contain commas, e.g. cleanup.policy=compact,delete.
+func parseConfigEntries(pairs []string) (map[string]*string, error) {
+	entries := make(map[string]*string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid config entry %q, expected key=value", pair)
+		}
+		entries[name] = &value
+	}
+	return entries, nil
+}
+
+// confirm asks a yes/no question on stdin, anything but y/yes is a no
+func confirm(question string) bool {
+	fmt.Fprint(os.Stderr, question, " [y/N] ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
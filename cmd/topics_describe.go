@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/philipparndt/go-logger"
+	"gokcat/config"
+	"gokcat/internal/kafka"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// topicsDescribeCmd represents the topics describe command
+var topicsDescribeCmd = &cobra.Command{
+	Use:   "describe <topic>",
+	Short: "Describe the partitions and configuration of a topic",
+	Long: `Describe a Kafka topic: leader, replicas, in-sync and offline replicas, watermarks and
+message count of each partition, followed by the configuration entries set on the topic.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+
+		runTopicsDescribe(args[0], cfg)
+	},
+}
+
+func init() {
+	topicsCmd.AddCommand(topicsDescribeCmd)
+	topicsDescribeCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the description in JSON format")
+}
+
+func runTopicsDescribe(topic string, cfg config.Config) {
+	client, admin := newClusterAdmin(cfg)
+	defer admin.Close()
+
+	description, err := kafka.DescribeTopic(client, admin, topic)
+	if err != nil {
+		logger.Panic("Failed to describe topic", err)
+	}
+
+	if jsonOutput {
+		printJSON(description)
+		return
+	}
+
+	printTopicDescription(description)
+}
+
+func printTopicDescription(description kafka.TopicDescription) {
+	fmt.Println("Topic:", description.Name)
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PARTITION\tLEADER\tREPLICAS\tISR\tOFFLINE\tLOW\tHIGH\tMESSAGES")
+	total := int64(0)
+	for _, p := range description.Partitions {
+		leader := "none"
+		if p.Leader >= 0 {
+			leader = strconv.Itoa(int(p.Leader))
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%d\n",
+			p.Partition, leader, joinIDs(p.Replicas), joinIDs(p.Isr), joinIDs(p.OfflineReplicas),
+			p.LowWatermark, p.HighWatermark, p.Messages)
+		total += p.Messages
+	}
+	fmt.Fprintf(w, "\t\t\t\t\t\t\t%d\n", total)
+	_ = w.Flush()
+
+	fmt.Println()
+	if len(description.Configs) == 0 {
+		fmt.Println("No configuration overrides")
+		return
+	}
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONFIG\tVALUE")
+	for _, c := range description.Configs {
+		value := c.Value
+		if c.Sensitive {
+			value = "(sensitive)"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", c.Name, value)
+	}
+	_ = w.Flush()
+}
+
+// joinIDs formats broker IDs as a comma-separated list, "-" if empty
+func joinIDs(ids []int32) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(int(id))
+	}
+	return strings.Join(parts, ",")
+}
@@ -2,30 +2,72 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/philipparndt/go-logger"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 )
 
 type Config struct {
-	Broker         string `json:"broker"`
+	Broker  string  `json:"broker"`
+	Brokers Brokers `json:"brokers,omitempty"`
+	Kafka   struct {
+		ClientId        string `json:"clientId,omitempty"`
+		Version         string `json:"version,omitempty"`
+		DialTimeout     string `json:"dialTimeout,omitempty"`
+		ReadTimeout     string `json:"readTimeout,omitempty"`
+		WriteTimeout    string `json:"writeTimeout,omitempty"`
+		AdminTimeout    string `json:"adminTimeout,omitempty"`
+		MetadataRefresh string `json:"metadataRefresh,omitempty"`
+		MetadataFull    *bool  `json:"metadataFull,omitempty"`
+	} `json:"kafka"`
 	SchemaRegistry struct {
 		Url      string `json:"url"`
 		Username string `json:"username,omitempty"`
 		Password string `json:"password,omitempty"`
 		Insecure bool   `json:"insecure,omitempty"`
+		CacheDir string `json:"cacheDir,omitempty"`
 	} `json:"schemaRegistry"`
 	Certs struct {
+		Enabled    *bool  `json:"enabled,omitempty"`
 		Ca         string `json:"ca"`
 		ClientCert string `json:"clientCert"`
 		ClientKey  string `json:"clientKey"`
 		Insecure   bool   `json:"insecure,omitempty"`
 	} `json:"certs"`
+	Sasl struct {
+		Mechanism    string   `json:"mechanism"`
+		Username     string   `json:"username,omitempty"`
+		Password     string   `json:"password,omitempty"`
+		TokenUrl     string   `json:"tokenUrl,omitempty"`
+		ClientId     string   `json:"clientId,omitempty"`
+		ClientSecret string   `json:"clientSecret,omitempty"`
+		Scopes       []string `json:"scopes,omitempty"`
+	} `json:"sasl"`
 	LogLevel string `json:"logLevel"`
 }
 
+// Brokers is a list of brokers, given either as a JSON array or as a comma-separated string
+type Brokers []string
+
+func (b *Brokers) UnmarshalJSON(data []byte) error {
+	var list string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*b = ParseBrokers(list)
+		return nil
+	}
+
+	var brokers []string
+	if err := json.Unmarshal(data, &brokers); err != nil {
+		return fmt.Errorf("brokers must be a string or an array of strings: %w", err)
+	}
+	*b = brokers
+	return nil
+}
+
 func ReplaceEnvVariables(input []byte) []byte {
 	envVariableRegex := regexp.MustCompile(`\${([^}]+)}`)
 
@@ -55,7 +97,7 @@ func LoadConfig(file string) (Config, error) {
 		return Config{}, err
 	}
 
-	updateCertsPath(configFile, &cfg)
+	updatePaths(configFile, &cfg)
 
 	if cfg.LogLevel == "" {
 		cfg.LogLevel = "debug"
@@ -66,6 +108,25 @@ func LoadConfig(file string) (Config, error) {
 	return cfg, nil
 }
 
+// BootstrapBrokers returns the brokers of the "brokers" array and the comma-separated "broker" string
+func (c Config) BootstrapBrokers() []string {
+	return ParseBrokers(append(append([]string{}, c.Brokers...), c.Broker)...)
+}
+
+// ParseBrokers splits comma-separated broker lists and drops empty and duplicate entries
+func ParseBrokers(values ...string) []string {
+	brokers := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, broker := range strings.Split(value, ",") {
+			broker = strings.TrimSpace(broker)
+			if broker != "" && !slices.Contains(brokers, broker) {
+				brokers = append(brokers, broker)
+			}
+		}
+	}
+	return brokers
+}
+
 func expandPath(path string) string {
 	if strings.HasPrefix(path, "~") {
 		home, err := os.UserHomeDir()
@@ -76,7 +137,7 @@ func expandPath(path string) string {
 	return path
 }
 
-func updateCertsPath(file string, cfg *Config) {
+func updatePaths(file string, cfg *Config) {
 	configDir := ""
 	absFile, err := filepath.Abs(file)
 	if err == nil {
@@ -93,4 +154,10 @@ func updateCertsPath(file string, cfg *Config) {
 	if cfg.Certs.ClientKey != "" && !filepath.IsAbs(cfg.Certs.ClientKey) {
 		cfg.Certs.ClientKey = filepath.Join(configDir, cfg.Certs.ClientKey)
 	}
+	if cfg.SchemaRegistry.CacheDir != "" {
+		cfg.SchemaRegistry.CacheDir = expandPath(cfg.SchemaRegistry.CacheDir)
+		if !filepath.IsAbs(cfg.SchemaRegistry.CacheDir) {
+			cfg.SchemaRegistry.CacheDir = filepath.Join(configDir, cfg.SchemaRegistry.CacheDir)
+		}
+	}
 }
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBootstrapBrokers(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"legacy broker", `{"broker": "a:9092"}`, []string{"a:9092"}},
+		{"legacy broker list", `{"broker": "a:9092, b:9092"}`, []string{"a:9092", "b:9092"}},
+		{"brokers array", `{"brokers": ["a:9092", "b:9092"]}`, []string{"a:9092", "b:9092"}},
+		{"brokers string", `{"brokers": "a:9092,b:9092,"}`, []string{"a:9092", "b:9092"}},
+		{"brokers and broker", `{"brokers": ["a:9092", "b:9092"], "broker": "b:9092,c:9092"}`, []string{"a:9092", "b:9092", "c:9092"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(file)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if got := cfg.BootstrapBrokers(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("BootstrapBrokers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrokersInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, []byte(`{"brokers": 9092}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(file); err == nil {
+		t.Fatal("LoadConfig() error = nil, want error for numeric brokers")
+	}
+}
@@ -0,0 +1,159 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+// TopicDescription describes the partitions and the non-default configuration of a topic
+type TopicDescription struct {
+	Name       string                 `json:"name"`
+	Partitions []PartitionDescription `json:"partitions"`
+	Configs    []ConfigValue          `json:"configs"`
+}
+
+// PartitionDescription describes the replicas and offsets of a partition, Leader is -1 if the partition has no leader
+type PartitionDescription struct {
+	Partition       int32   `json:"partition"`
+	Leader          int32   `json:"leader"`
+	Replicas        []int32 `json:"replicas"`
+	Isr             []int32 `json:"isr"`
+	OfflineReplicas []int32 `json:"offlineReplicas"`
+	LowWatermark    int64   `json:"lowWatermark"`
+	HighWatermark   int64   `json:"highWatermark"`
+	Messages        int64   `json:"messages"`
+}
+
+// ConfigValue is a single configuration entry of a topic
+type ConfigValue struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Source    string `json:"source"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+// DescribeTopic collects the partition metadata, watermarks and non-default configuration of a topic
+func DescribeTopic(client sarama.Client, admin sarama.ClusterAdmin, topic string) (TopicDescription, error) {
+	description := TopicDescription{Name: topic}
+
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return description, fmt.Errorf("failed to get partitions of topic %s: %w", topic, err)
+	}
+
+	for _, partition := range partitions {
+		p, err := describePartition(client, topic, partition)
+		if err != nil {
+			return description, err
+		}
+		description.Partitions = append(description.Partitions, p)
+	}
+
+	sort.Slice(description.Partitions, func(i, j int) bool {
+		return description.Partitions[i].Partition < description.Partitions[j].Partition
+	})
+
+	configs, err := TopicConfigs(admin, topic)
+	if err != nil {
+		return description, err
+	}
+	description.Configs = configs
+
+	return description, nil
+}
+
+func describePartition(client sarama.Client, topic string, partition int32) (PartitionDescription, error) {
+	p := PartitionDescription{Partition: partition, Leader: -1}
+
+	leader, err := client.Leader(topic, partition)
+	switch {
+	case err == nil:
+		p.Leader = leader.ID()
+	case !errors.Is(err, sarama.ErrLeaderNotAvailable):
+		return p, fmt.Errorf("failed to get leader of partition %d: %w", partition, err)
+	}
+
+	if p.Replicas, err = client.Replicas(topic, partition); err != nil {
+		return p, fmt.Errorf("failed to get replicas of partition %d: %w", partition, err)
+	}
+	if p.Isr, err = client.InSyncReplicas(topic, partition); err != nil {
+		return p, fmt.Errorf("failed to get in-sync replicas of partition %d: %w", partition, err)
+	}
+	if p.OfflineReplicas, err = client.OfflineReplicas(topic, partition); err != nil {
+		return p, fmt.Errorf("failed to get offline replicas of partition %d: %w", partition, err)
+	}
+
+	// Watermarks are only available from the leader
+	if p.Leader >= 0 {
+		if p.LowWatermark, p.HighWatermark, err = Watermarks(client, topic, partition); err != nil {
+			return p, err
+		}
+		p.Messages = p.HighWatermark - p.LowWatermark
+	}
+
+	return p, nil
+}
+
+// TopicConfigs returns the configuration entries that are set on the topic itself, sorted by name
+func TopicConfigs(admin sarama.ClusterAdmin, topic string) ([]ConfigValue, error) {
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: topic})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe config of topic %s: %w", topic, err)
+	}
+
+	configs := make([]ConfigValue, 0)
+	for _, entry := range entries {
+		// Brokers before Kafka 1.1 only report the Default flag and no source
+		if entry.Source != sarama.SourceTopic && (entry.Source != sarama.SourceUnknown || entry.Default) {
+			continue
+		}
+		configs = append(configs, ConfigValue{
+			Name:      entry.Name,
+			Value:     entry.Value,
+			Source:    entry.Source.String(),
+			Sensitive: entry.Sensitive,
+		})
+	}
+
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Name < configs[j].Name
+	})
+	return configs, nil
+}
+
+// TopicDefaults returns the default number of partitions and replication factor of new topics,
+// as configured on the controller broker
+func TopicDefaults(admin sarama.ClusterAdmin) (int32, int16, error) {
+	_, controller, err := admin.DescribeCluster()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to describe cluster: %w", err)
+	}
+
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{
+		Type:        sarama.BrokerResource,
+		Name:        strconv.Itoa(int(controller)),
+		ConfigNames: []string{"num.partitions", "default.replication.factor"},
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to describe config of broker %d: %w", controller, err)
+	}
+
+	partitions, replicationFactor := int64(1), int64(1)
+	for _, entry := range entries {
+		value, err := strconv.ParseInt(entry.Value, 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid broker config %s=%s: %w", entry.Name, entry.Value, err)
+		}
+		switch entry.Name {
+		case "num.partitions":
+			partitions = value
+		case "default.replication.factor":
+			replicationFactor = value
+		}
+	}
+	return int32(partitions), int16(replicationFactor), nil
+}
@@ -0,0 +1,123 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"gokcat/config"
+)
+
+// DefaultClientID identifies gokcat towards the brokers unless a client ID is configured
+const DefaultClientID = "gokcat"
+
+// NewConfig builds the sarama config shared by all commands from the configuration:
+// client ID, protocol version, timeouts, metadata refresh, TLS and SASL
+func NewConfig(cfg config.Config) (*sarama.Config, error) {
+	kConfig := sarama.NewConfig()
+
+	kConfig.ClientID = DefaultClientID
+	if cfg.Kafka.ClientId != "" {
+		kConfig.ClientID = cfg.Kafka.ClientId
+	}
+
+	if cfg.Kafka.Version != "" {
+		version, err := sarama.ParseKafkaVersion(cfg.Kafka.Version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kafka version %q: %w", cfg.Kafka.Version, err)
+		}
+		kConfig.Version = version
+	}
+
+	durations := []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"dialTimeout", cfg.Kafka.DialTimeout, &kConfig.Net.DialTimeout},
+		{"readTimeout", cfg.Kafka.ReadTimeout, &kConfig.Net.ReadTimeout},
+		{"writeTimeout", cfg.Kafka.WriteTimeout, &kConfig.Net.WriteTimeout},
+		{"adminTimeout", cfg.Kafka.AdminTimeout, &kConfig.Admin.Timeout},
+		{"metadataRefresh", cfg.Kafka.MetadataRefresh, &kConfig.Metadata.RefreshFrequency},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", d.name, d.value, err)
+		}
+		*d.target = duration
+	}
+
+	if cfg.Kafka.MetadataFull != nil {
+		kConfig.Metadata.Full = *cfg.Kafka.MetadataFull
+	}
+
+	useTLS, err := UseTLS(cfg.Certs.Enabled, cfg.Certs.ClientCert, cfg.Certs.ClientKey, cfg.Certs.Ca, cfg.Certs.Insecure)
+	if err != nil {
+		return nil, fmt.Errorf("inconsistent TLS config: %w", err)
+	}
+
+	if useTLS {
+		tlsConfig, err := NewTLSConfig(cfg.Certs.ClientCert, cfg.Certs.ClientKey, cfg.Certs.Ca, cfg.Certs.Insecure)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
+		kConfig.Net.TLS.Enable = true
+		kConfig.Net.TLS.Config = tlsConfig
+	}
+
+	err = ConfigureSASL(kConfig, SASLConfig{
+		Mechanism:    cfg.Sasl.Mechanism,
+		Username:     cfg.Sasl.Username,
+		Password:     cfg.Sasl.Password,
+		TokenURL:     cfg.Sasl.TokenUrl,
+		ClientID:     cfg.Sasl.ClientId,
+		ClientSecret: cfg.Sasl.ClientSecret,
+		Scopes:       cfg.Sasl.Scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure SASL: %w", err)
+	}
+
+	return kConfig, nil
+}
+
+// NewClient connects to the bootstrap brokers of the configuration. The sarama config is usually created with NewConfig
+// and adjusted by the command, e.g. with producer or consumer group settings.
+func NewClient(cfg config.Config, kConfig *sarama.Config) (sarama.Client, error) {
+	brokers := cfg.BootstrapBrokers()
+	if len(brokers) == 0 {
+		return nil, errors.New("no brokers configured, set broker or brokers in the config or use --brokers")
+	}
+
+	client, err := sarama.NewClient(brokers, kConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", strings.Join(brokers, ","), err)
+	}
+	return client, nil
+}
+
+// NewClusterAdmin creates a client and a cluster admin on top of it. Closing the admin also closes the client.
+func NewClusterAdmin(cfg config.Config) (sarama.Client, sarama.ClusterAdmin, error) {
+	kConfig, err := NewConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := NewClient(cfg, kConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("failed to create cluster admin: %w", err)
+	}
+	return client, admin, nil
+}
@@ -0,0 +1,216 @@
+package kafka
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/IBM/sarama"
+)
+
+// GroupSummary is a consumer group as shown by the group list
+type GroupSummary struct {
+	Group        string `json:"group"`
+	State        string `json:"state"`
+	ProtocolType string `json:"protocolType"`
+	Members      int    `json:"members"`
+}
+
+// GroupDescription describes the members and the committed offsets of a consumer group
+type GroupDescription struct {
+	Group    string        `json:"group"`
+	State    string        `json:"state"`
+	Protocol string        `json:"protocol"`
+	Members  []GroupMember `json:"members"`
+	Topics   []TopicLag    `json:"topics"`
+}
+
+// GroupMember is a member of a consumer group with the partitions assigned to it
+type GroupMember struct {
+	MemberID   string             `json:"memberId"`
+	ClientID   string             `json:"clientId"`
+	Host       string             `json:"host"`
+	Assignment map[string][]int32 `json:"assignment"`
+}
+
+// TopicLag contains the offsets of the group for all partitions of a topic, Lag is the sum over all partitions
+type TopicLag struct {
+	Topic      string         `json:"topic"`
+	Partitions []PartitionLag `json:"partitions"`
+	Lag        int64          `json:"lag"`
+}
+
+// PartitionLag contains the committed offset and lag of a partition, both are -1 if the group has not committed an offset
+type PartitionLag struct {
+	Partition int32  `json:"partition"`
+	Committed int64  `json:"committed"`
+	LogEnd    int64  `json:"logEnd"`
+	Lag       int64  `json:"lag"`
+	MemberID  string `json:"memberId,omitempty"`
+}
+
+// ListGroups returns all consumer groups of the cluster sorted by name
+func ListGroups(admin sarama.ClusterAdmin) ([]GroupSummary, error) {
+	groups, err := admin.ListConsumerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return []GroupSummary{}, nil
+	}
+
+	descriptions, err := admin.DescribeConsumerGroups(names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe consumer groups: %w", err)
+	}
+
+	states := make(map[string]*sarama.GroupDescription, len(descriptions))
+	for _, d := range descriptions {
+		states[d.GroupId] = d
+	}
+
+	result := make([]GroupSummary, 0, len(names))
+	for _, name := range names {
+		summary := GroupSummary{Group: name, ProtocolType: groups[name]}
+		if d := states[name]; d != nil {
+			summary.State = d.State
+			summary.Members = len(d.Members)
+		}
+		result = append(result, summary)
+	}
+	return result, nil
+}
+
+// DescribeGroup collects the members of a consumer group and the committed offset, log-end offset and lag
+// of every partition the group either committed an offset for or has assigned to a member
+func DescribeGroup(client sarama.Client, admin sarama.ClusterAdmin, group string) (GroupDescription, error) {
+	description := GroupDescription{Group: group}
+
+	descriptions, err := admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return description, fmt.Errorf("failed to describe consumer group %s: %w", group, err)
+	}
+	if len(descriptions) != 1 {
+		return description, fmt.Errorf("consumer group %s not found", group)
+	}
+	if descriptions[0].Err != sarama.ErrNoError {
+		return description, fmt.Errorf("failed to describe consumer group %s: %w", group, descriptions[0].Err)
+	}
+
+	d := descriptions[0]
+	description.State = d.State
+	description.Protocol = d.Protocol
+
+	owners := make(map[string]map[int32]string)
+	for _, m := range d.Members {
+		member := GroupMember{
+			MemberID:   m.MemberId,
+			ClientID:   m.ClientId,
+			Host:       m.ClientHost,
+			Assignment: map[string][]int32{},
+		}
+
+		// Only consumer groups use the consumer protocol assignment format
+		if d.ProtocolType == "consumer" {
+			if assignment, err := m.GetMemberAssignment(); err == nil && assignment != nil {
+				member.Assignment = assignment.Topics
+			}
+		}
+
+		for topic, partitions := range member.Assignment {
+			if owners[topic] == nil {
+				owners[topic] = make(map[int32]string)
+			}
+			for _, partition := range partitions {
+				owners[topic][partition] = member.MemberID
+			}
+		}
+		description.Members = append(description.Members, member)
+	}
+
+	sort.Slice(description.Members, func(i, j int) bool {
+		return description.Members[i].MemberID < description.Members[j].MemberID
+	})
+
+	committed, err := CommittedOffsets(admin, group)
+	if err != nil {
+		return description, err
+	}
+
+	// Assigned partitions without a committed offset are listed as well
+	for topic, partitions := range owners {
+		if committed[topic] == nil {
+			committed[topic] = make(map[int32]int64)
+		}
+		for partition := range partitions {
+			if _, ok := committed[topic][partition]; !ok {
+				committed[topic][partition] = -1
+			}
+		}
+	}
+
+	for topic, offsets := range committed {
+		topicLag := TopicLag{Topic: topic}
+		for partition, offset := range offsets {
+			logEnd, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return description, fmt.Errorf("failed to get log-end offset of topic %s partition %d: %w", topic, partition, err)
+			}
+
+			p := PartitionLag{
+				Partition: partition,
+				Committed: offset,
+				LogEnd:    logEnd,
+				Lag:       -1,
+				MemberID:  owners[topic][partition],
+			}
+			if offset >= 0 {
+				p.Lag = max(logEnd-offset, 0)
+				topicLag.Lag += p.Lag
+			}
+			topicLag.Partitions = append(topicLag.Partitions, p)
+		}
+
+		sort.Slice(topicLag.Partitions, func(i, j int) bool {
+			return topicLag.Partitions[i].Partition < topicLag.Partitions[j].Partition
+		})
+		description.Topics = append(description.Topics, topicLag)
+	}
+
+	sort.Slice(description.Topics, func(i, j int) bool {
+		return description.Topics[i].Topic < description.Topics[j].Topic
+	})
+
+	return description, nil
+}
+
+// CommittedOffsets returns the committed offsets of a consumer group by topic and partition
+func CommittedOffsets(admin sarama.ClusterAdmin, group string) (map[string]map[int32]int64, error) {
+	response, err := admin.ListConsumerGroupOffsets(group, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get offsets of consumer group %s: %w", group, err)
+	}
+	if response.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("failed to get offsets of consumer group %s: %w", group, response.Err)
+	}
+
+	offsets := make(map[string]map[int32]int64, len(response.Blocks))
+	for topic, blocks := range response.Blocks {
+		for partition, block := range blocks {
+			if block.Err != sarama.ErrNoError || block.Offset < 0 {
+				continue
+			}
+			if offsets[topic] == nil {
+				offsets[topic] = make(map[int32]int64)
+			}
+			offsets[topic][partition] = block.Offset
+		}
+	}
+	return offsets, nil
+}
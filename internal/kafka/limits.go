@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Limits are the stop conditions of a read, per partition and for the whole run
+type Limits struct {
+	// UntilOffset is the last offset to consume in each partition, -1 for no limit
+	UntilOffset int64
+	// UntilTime stops each partition at the first message at or after this time, zero for no limit
+	UntilTime time.Time
+	// Count is the maximum number of messages of the whole run, 0 for no limit
+	Count int
+}
+
+// NoLimits returns limits that never stop a read
+func NoLimits() Limits {
+	return Limits{UntilOffset: -1}
+}
+
+// Apply restricts a partition range to the per partition limits
+func (l Limits) Apply(r PartitionRange) PartitionRange {
+	if l.UntilOffset >= 0 && (r.End < 0 || l.UntilOffset < r.End) {
+		r.End = l.UntilOffset
+	}
+	r.EndTime = l.UntilTime
+	return r
+}
+
+// Reached reports whether the whole run is complete after count messages
+func (l Limits) Reached(count int) bool {
+	return l.Count > 0 && count >= l.Count
+}
+
+// Excludes reports whether the message lies beyond the range and must not be emitted
+func (r PartitionRange) Excludes(msg *sarama.ConsumerMessage) bool {
+	if r.End >= 0 && msg.Offset > r.End {
+		return true
+	}
+	return !r.EndTime.IsZero() && !msg.Timestamp.Before(r.EndTime)
+}
+
+// Completes reports whether the message is the last one of the range
+func (r PartitionRange) Completes(msg *sarama.ConsumerMessage) bool {
+	return r.End >= 0 && msg.Offset >= r.End
+}
+
+// IdleInterval is how long a bounded partition must be idle before its high watermark is checked, see EndReached
+const IdleInterval = time.Second
+
+// EndReached reports whether an idle partition has nothing left to consume up to end, given the position
+// (offset after the last delivered message) and the high watermark. The offset end itself may be a transaction marker,
+// so no message arrives for it.
+func EndReached(end int64, position int64, highWaterMark int64) bool {
+	return end >= 0 && position >= end && highWaterMark > end
+}
@@ -0,0 +1,69 @@
+package kafka
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+type StartKind int
+
+const (
+	// StartOldest starts at the oldest available offset
+	StartOldest StartKind = iota
+	// StartOffset starts at an absolute offset in each partition
+	StartOffset
+	// StartTail starts N messages before the end of each partition
+	StartTail
+	// StartTimestamp starts at the first message written at or after a point in time
+	StartTimestamp
+)
+
+// StartPosition describes where consumption starts in each partition
+type StartPosition struct {
+	Kind   StartKind
+	Offset int64
+	Tail   int64
+	Time   time.Time
+}
+
+// Resolve returns the absolute start offset of the partition, given its oldest offset and high watermark
+func (s StartPosition) Resolve(client sarama.Client, topic string, partition int32, oldest int64, newest int64) (int64, error) {
+	switch s.Kind {
+	case StartOffset:
+		return clamp(s.Offset, oldest, newest), nil
+	case StartTail:
+		return clamp(newest-s.Tail, oldest, newest), nil
+	case StartTimestamp:
+		offset, err := client.GetOffset(topic, partition, s.Time.UnixMilli())
+		if err != nil {
+			return 0, fmt.Errorf("failed to get offset for time %s in partition %d: %w", s.Time.Format(time.RFC3339), partition, err)
+		}
+		if offset < 0 {
+			// no message at or after the timestamp
+			return newest, nil
+		}
+		return clamp(offset, oldest, newest), nil
+	default:
+		return oldest, nil
+	}
+}
+
+// ParseTimestamp parses a Unix timestamp in milliseconds or an RFC3339 time
+func ParseTimestamp(value string) (time.Time, error) {
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(millis), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q, expected milliseconds since epoch or RFC3339", value)
+	}
+	return t, nil
+}
+
+func clamp(offset int64, oldest int64, newest int64) int64 {
+	return max(oldest, min(offset, newest))
+}
@@ -0,0 +1,36 @@
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+)
+
+// ExplicitPartition marks a producer message whose Partition field must be used as is.
+// Set it as the Metadata of the sarama.ProducerMessage.
+type ExplicitPartition struct{}
+
+// NewRecordPartitioner returns a partitioner that keeps explicitly chosen partitions and hashes the key otherwise
+func NewRecordPartitioner(topic string) sarama.Partitioner {
+	return &recordPartitioner{
+		manual: sarama.NewManualPartitioner(topic),
+		hash:   sarama.NewHashPartitioner(topic),
+	}
+}
+
+type recordPartitioner struct {
+	manual sarama.Partitioner
+	hash   sarama.Partitioner
+}
+
+func (p *recordPartitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if _, ok := message.Metadata.(ExplicitPartition); ok {
+		if message.Partition < 0 || message.Partition >= numPartitions {
+			return -1, sarama.ErrInvalidPartition
+		}
+		return p.manual.Partition(message, numPartitions)
+	}
+	return p.hash.Partition(message, numPartitions)
+}
+
+func (p *recordPartitioner) RequiresConsistency() bool {
+	return true
+}
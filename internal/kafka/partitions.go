@@ -0,0 +1,128 @@
+package kafka
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// PartitionRange describes which offsets of a single partition to consume.
+type PartitionRange struct {
+	Partition int32
+	// Start is the first offset to consume, or one of sarama.OffsetOldest/sarama.OffsetNewest
+	Start int64
+	// End is the last offset to consume, -1 to keep following the partition
+	End int64
+	// EndTime ends the partition at the first message at or after this time, zero to ignore timestamps
+	EndTime time.Time
+}
+
+// SelectPartitions returns all partitions of the topic, limited to the selected ones if any are given
+func SelectPartitions(client sarama.Client, topic string, selected []int32) ([]int32, error) {
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions of topic %s: %w", topic, err)
+	}
+
+	if len(selected) == 0 {
+		return partitions, nil
+	}
+
+	result := make([]int32, 0, len(selected))
+	for _, partition := range selected {
+		if !slices.Contains(partitions, partition) {
+			return nil, fmt.Errorf("topic %s has no partition %d", topic, partition)
+		}
+		if !slices.Contains(result, partition) {
+			result = append(result, partition)
+		}
+	}
+
+	slices.Sort(result)
+	return result, nil
+}
+
+// Watermarks returns the oldest available offset and the high watermark (next offset to be written) of a partition
+func Watermarks(client sarama.Client, topic string, partition int32) (int64, int64, error) {
+	oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get oldest offset of partition %d: %w", partition, err)
+	}
+
+	newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get newest offset of partition %d: %w", partition, err)
+	}
+
+	return oldest, newest, nil
+}
+
+// ConsumePartitions consumes all given partition ranges concurrently and merges the messages into one channel.
+// The channel is closed once every partition reached the end of its range or done is closed.
+func ConsumePartitions(consumer sarama.Consumer, topic string, ranges []PartitionRange, done <-chan struct{}) (<-chan *sarama.ConsumerMessage, error) {
+	pcs := make([]sarama.PartitionConsumer, 0, len(ranges))
+	for _, r := range ranges {
+		pc, err := consumer.ConsumePartition(topic, r.Partition, r.Start)
+		if err != nil {
+			for _, open := range pcs {
+				open.Close()
+			}
+			return nil, fmt.Errorf("failed to consume partition %d: %w", r.Partition, err)
+		}
+		pcs = append(pcs, pc)
+	}
+
+	messages := make(chan *sarama.ConsumerMessage)
+	var wg sync.WaitGroup
+
+	for i, pc := range pcs {
+		wg.Add(1)
+		go func(r PartitionRange, pc sarama.PartitionConsumer) {
+			defer wg.Done()
+			defer pc.Close()
+
+			idle := time.NewTicker(IdleInterval)
+			defer idle.Stop()
+			active := false
+			position := r.Start
+
+			for {
+				select {
+				case msg, ok := <-pc.Messages():
+					if !ok || r.Excludes(msg) {
+						return
+					}
+					active = true
+					position = msg.Offset + 1
+
+					select {
+					case messages <- msg:
+					case <-done:
+						return
+					}
+
+					if r.Completes(msg) {
+						return
+					}
+				case <-idle.C:
+					if !active && EndReached(r.End, position, pc.HighWaterMarkOffset()) {
+						return
+					}
+					active = false
+				case <-done:
+					return
+				}
+			}
+		}(ranges[i], pc)
+	}
+
+	go func() {
+		wg.Wait()
+		close(messages)
+	}()
+
+	return messages, nil
+}
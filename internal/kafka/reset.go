@@ -0,0 +1,169 @@
+package kafka
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+type ResetKind int
+
+const (
+	// ResetEarliest resets to the oldest available offset
+	ResetEarliest ResetKind = iota
+	// ResetLatest resets to the high watermark, skipping all existing messages
+	ResetLatest
+	// ResetToOffset resets to an absolute offset in each partition
+	ResetToOffset
+	// ResetToTime resets to the first message written at or after a point in time
+	ResetToTime
+	// ResetShiftBy moves the committed offset by a number of messages, negative values move backwards
+	ResetShiftBy
+	// ResetToPlan resets to the offsets of a previously exported plan
+	ResetToPlan
+)
+
+// OffsetReset describes the new offset of each partition of a consumer group
+type OffsetReset struct {
+	Kind   ResetKind
+	Offset int64
+	Time   time.Time
+	Shift  int64
+	// Plan contains the offsets by topic and partition for ResetToPlan
+	Plan map[string]map[int32]int64
+}
+
+// TopicPartitions selects partitions of a topic, all partitions if none are given
+type TopicPartitions struct {
+	Topic      string
+	Partitions []int32
+}
+
+// OffsetPlanEntry is the current and the new committed offset of a partition, Current is -1 if the group has not committed an offset
+type OffsetPlanEntry struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Current   int64  `json:"current"`
+	Offset    int64  `json:"offset"`
+}
+
+// EnsureGroupInactive returns an error if the consumer group has active members, offsets can only be reset for inactive groups
+func EnsureGroupInactive(admin sarama.ClusterAdmin, group string) error {
+	descriptions, err := admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return fmt.Errorf("failed to describe consumer group %s: %w", group, err)
+	}
+
+	for _, d := range descriptions {
+		if d.Err != sarama.ErrNoError {
+			return fmt.Errorf("failed to describe consumer group %s: %w", group, d.Err)
+		}
+		if len(d.Members) > 0 {
+			return fmt.Errorf("consumer group %s has %d active members (state %s), stop all consumers before resetting offsets", group, len(d.Members), d.State)
+		}
+	}
+	return nil
+}
+
+// PlanOffsetReset computes the new offset of every selected partition, clamped to the available offsets
+func PlanOffsetReset(client sarama.Client, committed map[string]map[int32]int64, scope []TopicPartitions, reset OffsetReset) ([]OffsetPlanEntry, error) {
+	plan := make([]OffsetPlanEntry, 0)
+	for _, selection := range scope {
+		partitions, err := SelectPartitions(client, selection.Topic, selection.Partitions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, partition := range partitions {
+			current, ok := committed[selection.Topic][partition]
+			if !ok {
+				current = -1
+			}
+
+			if reset.Kind == ResetToPlan {
+				if _, ok := reset.Plan[selection.Topic][partition]; !ok {
+					continue
+				}
+			}
+
+			offset, err := reset.resolve(client, selection.Topic, partition, current)
+			if err != nil {
+				return nil, err
+			}
+
+			plan = append(plan, OffsetPlanEntry{
+				Topic:     selection.Topic,
+				Partition: partition,
+				Current:   current,
+				Offset:    offset,
+			})
+		}
+	}
+
+	sort.Slice(plan, func(i, j int) bool {
+		if plan[i].Topic != plan[j].Topic {
+			return plan[i].Topic < plan[j].Topic
+		}
+		return plan[i].Partition < plan[j].Partition
+	})
+	return plan, nil
+}
+
+func (r OffsetReset) resolve(client sarama.Client, topic string, partition int32, current int64) (int64, error) {
+	oldest, newest, err := Watermarks(client, topic, partition)
+	if err != nil {
+		return 0, err
+	}
+
+	switch r.Kind {
+	case ResetLatest:
+		return newest, nil
+	case ResetToOffset:
+		return clamp(r.Offset, oldest, newest), nil
+	case ResetToTime:
+		return StartPosition{Kind: StartTimestamp, Time: r.Time}.Resolve(client, topic, partition, oldest, newest)
+	case ResetShiftBy:
+		if current < 0 {
+			return 0, fmt.Errorf("cannot shift partition %d of topic %s, the group has no committed offset", partition, topic)
+		}
+		return clamp(current+r.Shift, oldest, newest), nil
+	case ResetToPlan:
+		return clamp(r.Plan[topic][partition], oldest, newest), nil
+	default:
+		return oldest, nil
+	}
+}
+
+// CommitOffsets commits the planned offsets for the consumer group, which must not have active members
+func CommitOffsets(client sarama.Client, group string, plan []OffsetPlanEntry) error {
+	coordinator, err := client.Coordinator(group)
+	if err != nil {
+		return fmt.Errorf("failed to find coordinator of consumer group %s: %w", group, err)
+	}
+
+	request := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: -1,
+		RetentionTime:           -1,
+	}
+	for _, entry := range plan {
+		request.AddBlock(entry.Topic, entry.Partition, entry.Offset, 0, "")
+	}
+
+	response, err := coordinator.CommitOffset(request)
+	if err != nil {
+		return fmt.Errorf("failed to commit offsets of consumer group %s: %w", group, err)
+	}
+
+	for topic, partitions := range response.Errors {
+		for partition, kerr := range partitions {
+			if kerr != sarama.ErrNoError {
+				return fmt.Errorf("failed to commit offset of topic %s partition %d: %w", topic, partition, kerr)
+			}
+		}
+	}
+	return nil
+}
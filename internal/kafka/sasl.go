@@ -0,0 +1,117 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/xdg-go/scram"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// SASLConfig contains the SASL settings, an empty mechanism disables SASL.
+// PLAIN and SCRAM use username and password, OAUTHBEARER fetches tokens from TokenURL with the client credentials flow.
+type SASLConfig struct {
+	Mechanism    string
+	Username     string
+	Password     string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// ConfigureSASL enables SASL authentication on the sarama config
+func ConfigureSASL(kConfig *sarama.Config, cfg SASLConfig) error {
+	if cfg.Mechanism == "" {
+		return nil
+	}
+
+	mechanism := sarama.SASLMechanism(strings.ToUpper(cfg.Mechanism))
+	kConfig.Net.SASL.Enable = true
+	kConfig.Net.SASL.Mechanism = mechanism
+
+	switch mechanism {
+	case sarama.SASLTypePlaintext:
+		return configureCredentials(kConfig, cfg)
+	case sarama.SASLTypeSCRAMSHA256:
+		kConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{HashGeneratorFcn: scram.SHA256}
+		}
+		return configureCredentials(kConfig, cfg)
+	case sarama.SASLTypeSCRAMSHA512:
+		kConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{HashGeneratorFcn: scram.SHA512}
+		}
+		return configureCredentials(kConfig, cfg)
+	case sarama.SASLTypeOAuth:
+		if cfg.TokenURL == "" || cfg.ClientID == "" {
+			return fmt.Errorf("SASL mechanism %s requires a tokenUrl and a clientId", mechanism)
+		}
+		kConfig.Net.SASL.TokenProvider = newTokenProvider(cfg)
+		return nil
+	default:
+		return fmt.Errorf("unsupported SASL mechanism %s, valid mechanisms are %s, %s, %s and %s", cfg.Mechanism,
+			sarama.SASLTypePlaintext, sarama.SASLTypeSCRAMSHA256, sarama.SASLTypeSCRAMSHA512, sarama.SASLTypeOAuth)
+	}
+}
+
+func configureCredentials(kConfig *sarama.Config, cfg SASLConfig) error {
+	if cfg.Username == "" || cfg.Password == "" {
+		return fmt.Errorf("SASL mechanism %s requires a username and a password", kConfig.Net.SASL.Mechanism)
+	}
+	kConfig.Net.SASL.User = cfg.Username
+	kConfig.Net.SASL.Password = cfg.Password
+	return nil
+}
+
+// scramClient adapts xdg-go/scram to the sarama.SCRAMClient interface
+type scramClient struct {
+	*scram.Client
+	*scram.ClientConversation
+	scram.HashGeneratorFcn
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.HashGeneratorFcn.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.Client = client
+	c.ClientConversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.ClientConversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.ClientConversation.Done()
+}
+
+// tokenProvider fetches OAUTHBEARER tokens with the client credentials flow.
+// Tokens are cached and fetched again once they expire, so new connections always authenticate with a valid token.
+type tokenProvider struct {
+	source oauth2.TokenSource
+}
+
+func newTokenProvider(cfg SASLConfig) *tokenProvider {
+	credentials := clientcredentials.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		TokenURL:     cfg.TokenURL,
+		Scopes:       cfg.Scopes,
+	}
+	return &tokenProvider{source: credentials.TokenSource(context.Background())}
+}
+
+func (p *tokenProvider) Token() (*sarama.AccessToken, error) {
+	token, err := p.source.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OAuth token: %w", err)
+	}
+	return &sarama.AccessToken{Token: token.AccessToken}, nil
+}
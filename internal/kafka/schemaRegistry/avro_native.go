@@ -0,0 +1,447 @@
+package schemaRegistry
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+
+	av "github.com/hamba/avro/v2"
+)
+
+// toAvroNative converts a generic JSON value (as produced by encoding/json with UseNumber) into the
+// Go types hamba/avro expects for the schema. It accepts the JSON gokcat writes for Avro payloads in the rendering:
+// unions either unwrapped or wrapped as {"<type>": value}, and for AvroNatural timestamps as RFC3339,
+// bytes as base64 and decimals as "a/b" or decimal strings.
+func toAvroNative(schema av.Schema, v interface{}, rendering string) (interface{}, error) {
+	if ref, ok := schema.(*av.RefSchema); ok {
+		schema = ref.Schema()
+	}
+
+	switch s := schema.(type) {
+	case *av.NullSchema:
+		if v != nil {
+			return nil, fmt.Errorf("expected null, got %T", v)
+		}
+		return nil, nil
+	case *av.PrimitiveSchema:
+		return primitiveToAvro(s, v, rendering)
+	case *av.RecordSchema:
+		return recordToAvro(s, v, rendering)
+	case *av.EnumSchema:
+		symbol, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected enum symbol of %s, got %T", s.FullName(), v)
+		}
+		for _, candidate := range s.Symbols() {
+			if candidate == symbol {
+				return symbol, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown symbol %q of enum %s", symbol, s.FullName())
+	case *av.ArraySchema:
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected array, got %T", v)
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			converted, err := toAvroNative(s.Items(), item, rendering)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			result[i] = converted
+		}
+		return result, nil
+	case *av.MapSchema:
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected map, got %T", v)
+		}
+		result := make(map[string]interface{}, len(values))
+		for key, value := range values {
+			converted, err := toAvroNative(s.Values(), value, rendering)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			result[key] = converted
+		}
+		return result, nil
+	case *av.UnionSchema:
+		return unionToAvro(s, v, rendering)
+	case *av.FixedSchema:
+		return fixedToAvro(s, v, rendering)
+	default:
+		return nil, fmt.Errorf("unsupported Avro type %s", schema.Type())
+	}
+}
+
+func recordToAvro(s *av.RecordSchema, v interface{}, rendering string) (interface{}, error) {
+	values, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected record %s, got %T", s.FullName(), v)
+	}
+
+	result := make(map[string]interface{}, len(values))
+	for _, field := range s.Fields() {
+		value, ok := values[field.Name()]
+		if !ok {
+			if !field.HasDefault() {
+				return nil, fmt.Errorf("missing field %s of record %s", field.Name(), s.FullName())
+			}
+			continue
+		}
+
+		converted, err := toAvroNative(field.Type(), value, rendering)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.Name(), err)
+		}
+		result[field.Name()] = converted
+	}
+
+	for name := range values {
+		if _, ok := result[name]; !ok && fieldByName(s, name) == nil {
+			return nil, fmt.Errorf("unknown field %s of record %s", name, s.FullName())
+		}
+	}
+
+	return result, nil
+}
+
+func fieldByName(s *av.RecordSchema, name string) *av.Field {
+	for _, field := range s.Fields() {
+		if field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// unionToAvro converts to the {"<type>": value} form hamba/avro uses for generic unions.
+// A value that is already wrapped selects its branch explicitly, otherwise the first branch the value converts to is used.
+func unionToAvro(s *av.UnionSchema, v interface{}, rendering string) (interface{}, error) {
+	if v == nil {
+		if _, pos := s.Types().Get(string(av.Null)); pos < 0 {
+			return nil, fmt.Errorf("null is not allowed in union %s", s.String())
+		}
+		return map[string]interface{}{}, nil
+	}
+
+	if wrapped, ok := v.(map[string]interface{}); ok && len(wrapped) == 1 {
+		for name, value := range wrapped {
+			if branch := unionBranch(s, name); branch != nil {
+				converted, err := toAvroNative(branch, value, rendering)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				return map[string]interface{}{unionBranchName(branch): converted}, nil
+			}
+		}
+	}
+
+	for _, branch := range s.Types() {
+		if branch.Type() == av.Null {
+			continue
+		}
+		if converted, err := toAvroNative(branch, v, rendering); err == nil {
+			return map[string]interface{}{unionBranchName(branch): converted}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("value of type %T does not match any type of union %s", v, s.String())
+}
+
+// unionBranch finds a union branch by its full name, type name or type name with logical type
+func unionBranch(s *av.UnionSchema, name string) av.Schema {
+	for _, branch := range s.Types() {
+		if unionBranchName(branch) == name || string(branch.Type()) == name {
+			return branch
+		}
+	}
+	return nil
+}
+
+// unionBranchName is the name hamba/avro uses to identify a union branch
+func unionBranchName(schema av.Schema) string {
+	if ref, ok := schema.(*av.RefSchema); ok {
+		schema = ref.Schema()
+	}
+	if named, ok := schema.(av.NamedSchema); ok {
+		return named.FullName()
+	}
+
+	name := string(schema.Type())
+	if logical, ok := schema.(av.LogicalTypeSchema); ok && logical.Logical() != nil {
+		name += "." + string(logical.Logical().Type())
+	}
+	return name
+}
+
+func primitiveToAvro(s *av.PrimitiveSchema, v interface{}, rendering string) (interface{}, error) {
+	var logical av.LogicalType
+	if s.Logical() != nil {
+		logical = s.Logical().Type()
+	}
+
+	switch s.Type() {
+	case av.Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected boolean, got %T", v)
+		}
+		return b, nil
+	case av.String:
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		return str, nil
+	case av.Int:
+		switch logical {
+		case av.Date:
+			return toTime(v, time.Hour*24)
+		case av.TimeMillis:
+			return toDuration(v, durationUnit(time.Millisecond, rendering))
+		}
+		n, err := toInteger(v, math.MinInt32, math.MaxInt32)
+		return int(n), err
+	case av.Long:
+		switch logical {
+		case av.TimestampMillis, av.LocalTimestampMillis:
+			return toTime(v, time.Millisecond)
+		case av.TimestampMicros, av.LocalTimestampMicros:
+			return toTime(v, time.Microsecond)
+		case av.TimeMicros:
+			return toDuration(v, durationUnit(time.Microsecond, rendering))
+		}
+		return toInteger(v, math.MinInt64, math.MaxInt64)
+	case av.Float:
+		f, err := toFloat(v)
+		return float32(f), err
+	case av.Double:
+		return toFloat(v)
+	case av.Bytes:
+		if rendering == AvroJSON {
+			data, err := fromLatin1(v)
+			if err != nil || logical != av.Decimal {
+				return data, err
+			}
+			return ratFromBytes(data, s.Logical().(*av.DecimalLogicalSchema).Scale()), nil
+		}
+		if logical == av.Decimal {
+			return toRat(v)
+		}
+		return toBytes(v)
+	default:
+		return nil, fmt.Errorf("unsupported Avro type %s", s.Type())
+	}
+}
+
+func fixedToAvro(s *av.FixedSchema, v interface{}, rendering string) (interface{}, error) {
+	var logical av.LogicalType
+	if s.Logical() != nil {
+		logical = s.Logical().Type()
+	}
+	if logical == av.Decimal && rendering != AvroJSON {
+		return toRat(v)
+	}
+
+	var data []byte
+	switch value := v.(type) {
+	case string:
+		if rendering == AvroJSON {
+			decoded, err := fromLatin1(value)
+			if err != nil {
+				return nil, err
+			}
+			data = decoded
+			break
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected base64 encoded fixed %s: %w", s.FullName(), err)
+		}
+		data = decoded
+	case []interface{}:
+		for _, item := range value {
+			b, err := toInteger(item, 0, math.MaxUint8)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, byte(b))
+		}
+	case map[string]interface{}:
+		if logical != av.Duration {
+			return nil, fmt.Errorf("expected fixed %s, got %T", s.FullName(), v)
+		}
+		decoded, err := durationToBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	default:
+		return nil, fmt.Errorf("expected fixed %s, got %T", s.FullName(), v)
+	}
+
+	if len(data) != s.Size() {
+		return nil, fmt.Errorf("expected %d bytes for fixed %s, got %d", s.Size(), s.FullName(), len(data))
+	}
+	if logical == av.Decimal {
+		return ratFromBytes(data, s.Logical().(*av.DecimalLogicalSchema).Scale()), nil
+	}
+
+	array := reflect.New(reflect.ArrayOf(s.Size(), reflect.TypeOf(byte(0)))).Elem()
+	reflect.Copy(array, reflect.ValueOf(data))
+	return array.Interface(), nil
+}
+
+func toInteger(v interface{}, minValue int64, maxValue int64) (int64, error) {
+	var n int64
+	switch value := v.(type) {
+	case json.Number:
+		parsed, err := value.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("expected integer, got %s", value)
+		}
+		n = parsed
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, fmt.Errorf("expected integer, got %v", value)
+		}
+		n = int64(value)
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", v)
+	}
+
+	if n < minValue || n > maxValue {
+		return 0, fmt.Errorf("integer %d out of range", n)
+	}
+	return n, nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch value := v.(type) {
+	case json.Number:
+		return value.Float64()
+	case float64:
+		return value, nil
+	default:
+		return 0, fmt.Errorf("expected number, got %T", v)
+	}
+}
+
+func toBytes(v interface{}) ([]byte, error) {
+	str, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected base64 encoded bytes, got %T", v)
+	}
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("expected base64 encoded bytes: %w", err)
+	}
+	return data, nil
+}
+
+func toRat(v interface{}) (*big.Rat, error) {
+	var str string
+	switch value := v.(type) {
+	case string:
+		str = value
+	case json.Number:
+		str = value.String()
+	case float64:
+		return new(big.Rat).SetFloat64(value), nil
+	default:
+		return nil, fmt.Errorf("expected decimal, got %T", v)
+	}
+
+	r, ok := new(big.Rat).SetString(str)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", str)
+	}
+	return r, nil
+}
+
+// toTime accepts an RFC3339 time, a local time without offset, a date (2006-01-02) or a number of units since the epoch
+func toTime(v interface{}, unit time.Duration) (time.Time, error) {
+	if str, ok := v.(string); ok {
+		for _, layout := range []string{time.RFC3339Nano, localTimestampLayout, time.DateOnly} {
+			if t, err := time.Parse(layout, str); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid time %q", str)
+	}
+
+	n, err := toInteger(v, math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, 0).Add(time.Duration(n) * unit).UTC(), nil
+}
+
+// toDuration accepts a time of day (15:04:05.000000) or a number of units, see durationUnit
+func toDuration(v interface{}, unit time.Duration) (time.Duration, error) {
+	if str, ok := v.(string); ok {
+		t, err := time.Parse("15:04:05.999999999", str)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time of day %q", str)
+		}
+		return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())), nil
+	}
+
+	n, err := toInteger(v, math.MinInt64, math.MaxInt64)
+	return time.Duration(n) * unit, err
+}
+
+// durationUnit is the unit of times of day given as numbers: the unit of the logical type for AvroJSON,
+// nanoseconds as written by encoding/json for time.Duration otherwise
+func durationUnit(unit time.Duration, rendering string) time.Duration {
+	if rendering == AvroJSON {
+		return unit
+	}
+	return time.Nanosecond
+}
+
+// fromLatin1 decodes bytes encoded as a string with one code point per byte, as in the Avro JSON encoding
+func fromLatin1(v interface{}) ([]byte, error) {
+	str, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected ISO-8859-1 encoded bytes, got %T", v)
+	}
+
+	data := make([]byte, 0, len(str))
+	for _, r := range str {
+		if r > math.MaxUint8 {
+			return nil, fmt.Errorf("expected ISO-8859-1 encoded bytes, got %q", r)
+		}
+		data = append(data, byte(r))
+	}
+	return data, nil
+}
+
+// ratFromBytes decodes a decimal from the two's complement of its unscaled value
+func ratFromBytes(data []byte, scale int) *big.Rat {
+	n := new(big.Int).SetBytes(data)
+	if len(data) > 0 && data[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(data)*8)))
+	}
+	return new(big.Rat).SetFrac(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+}
+
+// durationToBytes encodes a duration as rendered by AvroNatural ({"months": 1, "days": 2, "milliseconds": 3})
+func durationToBytes(values map[string]interface{}) ([]byte, error) {
+	data := make([]byte, 12)
+	for i, name := range []string{"months", "days", "milliseconds"} {
+		n, err := toInteger(values[name], 0, math.MaxUint32)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		binary.LittleEndian.PutUint32(data[i*4:], uint32(n))
+	}
+	return data, nil
+}
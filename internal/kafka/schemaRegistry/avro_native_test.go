@@ -0,0 +1,138 @@
+package schemaRegistry
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	av "github.com/hamba/avro/v2"
+)
+
+// decodeJSONValue decodes JSON the way gokcat produce reads its input
+func decodeJSONValue(t *testing.T, input string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(input))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", input, err)
+	}
+	return v
+}
+
+func TestToAvroNative(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	tests := []struct {
+		name   string
+		schema string
+		input  string
+		want   interface{}
+	}{
+		{"boolean", `"boolean"`, `true`, true},
+		{"string", `"string"`, `"abc"`, "abc"},
+		{"int", `"int"`, `42`, 42},
+		{"long", `"long"`, `12345678901234`, int64(12345678901234)},
+		{"float", `"float"`, `1.5`, float32(1.5)},
+		{"double", `"double"`, `2.25`, 2.25},
+		{"bytes as base64", `"bytes"`, `"aGk="`, []byte("hi")},
+		{"null", `"null"`, `null`, nil},
+		{"decimal string", `{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}`, `"123.45"`, big.NewRat(12345, 100)},
+		{"decimal fraction", `{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}`, `"2469/20"`, big.NewRat(2469, 20)},
+		{"decimal number", `{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}`, `-1.5`, big.NewRat(-3, 2)},
+		{"fixed decimal", `{"type":"fixed","name":"D","size":8,"logicalType":"decimal","precision":10,"scale":3}`, `"-0.005"`, big.NewRat(-5, 1000)},
+		{"timestamp-millis RFC3339", `{"type":"long","logicalType":"timestamp-millis"}`, `"2024-01-02T03:04:05.006Z"`, timestamp},
+		{"timestamp-millis number", `{"type":"long","logicalType":"timestamp-millis"}`, `1704164645006`, timestamp},
+		{"timestamp-micros number", `{"type":"long","logicalType":"timestamp-micros"}`, `1704164645006000`, timestamp},
+		{"local-timestamp-millis", `{"type":"long","logicalType":"local-timestamp-millis"}`, `"2024-01-02T03:04:05.006"`, timestamp},
+		{"date", `{"type":"int","logicalType":"date"}`, `"2024-01-02"`, timestamp.Truncate(24 * time.Hour)},
+		{"date number", `{"type":"int","logicalType":"date"}`, `19724`, timestamp.Truncate(24 * time.Hour)},
+		{"time-millis", `{"type":"int","logicalType":"time-millis"}`, `"00:00:05.007"`, 5007 * time.Millisecond},
+		{"time-micros", `{"type":"long","logicalType":"time-micros"}`, `"00:00:00.001500"`, 1500 * time.Microsecond},
+		{"enum", `{"type":"enum","name":"E","symbols":["A","B"]}`, `"B"`, "B"},
+		{"fixed base64", `{"type":"fixed","name":"F","size":2}`, `"AQI="`, [2]byte{1, 2}},
+		{"fixed array", `{"type":"fixed","name":"F","size":2}`, `[1,2]`, [2]byte{1, 2}},
+		{"array", `{"type":"array","items":"int"}`, `[1,2]`, []interface{}{1, 2}},
+		{"map", `{"type":"map","values":"double"}`, `{"a":1.5}`, map[string]interface{}{"a": 1.5}},
+		{"nullable union null", `["null","string"]`, `null`, map[string]interface{}{}},
+		{"nullable union unwrapped", `["null","string"]`, `"x"`, map[string]interface{}{"string": "x"}},
+		{"union wrapped", `["null","int","string"]`, `{"string":"5"}`, map[string]interface{}{"string": "5"}},
+		{"union first matching branch", `["string","long"]`, `5`, map[string]interface{}{"long": int64(5)}},
+		{"union wrapped logical type", `["null",{"type":"long","logicalType":"timestamp-millis"}]`, `{"long":1704164645006}`, map[string]interface{}{"long.timestamp-millis": timestamp}},
+		{
+			"record with default",
+			`{"type":"record","name":"R","fields":[{"name":"id","type":"long"},{"name":"name","type":"string","default":"none"}]}`,
+			`{"id":1}`,
+			map[string]interface{}{"id": int64(1)},
+		},
+		{
+			"nullable record wrapped",
+			`["null",{"type":"record","name":"Inner","namespace":"ns","fields":[{"name":"x","type":"int"}]}]`,
+			`{"ns.Inner":{"x":1}}`,
+			map[string]interface{}{"ns.Inner": map[string]interface{}{"x": 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := av.MustParse(tt.schema)
+
+			got, err := toAvroNative(schema, decodeJSONValue(t, tt.input), AvroNatural)
+			if err != nil {
+				t.Fatalf("toAvroNative() error = %v", err)
+			}
+
+			if want, ok := tt.want.(*big.Rat); ok {
+				if r, ok := got.(*big.Rat); !ok || r.Cmp(want) != 0 {
+					t.Fatalf("toAvroNative() = %v, want %v", got, want)
+				}
+			} else if want, ok := tt.want.(time.Time); ok {
+				if ts, ok := got.(time.Time); !ok || !ts.Equal(want) {
+					t.Fatalf("toAvroNative() = %v, want %v", got, want)
+				}
+			} else if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("toAvroNative() = %#v, want %#v", got, tt.want)
+			}
+
+			if _, err := av.Marshal(schema, got); err != nil {
+				t.Fatalf("hamba/avro rejects the converted value: %v", err)
+			}
+		})
+	}
+}
+
+func TestToAvroNativeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		input  string
+		want   string
+	}{
+		{"int out of range", `"int"`, `2147483648`, "out of range"},
+		{"int fraction", `"int"`, `1.5`, "expected integer"},
+		{"string type", `"string"`, `1`, "expected string"},
+		{"invalid base64", `"bytes"`, `"%%%"`, "base64"},
+		{"invalid decimal", `{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}`, `"abc"`, "invalid decimal"},
+		{"invalid timestamp", `{"type":"long","logicalType":"timestamp-millis"}`, `"yesterday"`, "invalid time"},
+		{"unknown enum symbol", `{"type":"enum","name":"E","symbols":["A","B"]}`, `"C"`, "unknown symbol"},
+		{"fixed size", `{"type":"fixed","name":"F","size":2}`, `"AQID"`, "expected 2 bytes"},
+		{"null not in union", `["int","string"]`, `null`, "null is not allowed"},
+		{"no union branch", `["null","int"]`, `"x"`, "does not match any type"},
+		{"missing field", `{"type":"record","name":"R","fields":[{"name":"id","type":"long"}]}`, `{}`, "missing field id"},
+		{"unknown field", `{"type":"record","name":"R","fields":[{"name":"id","type":"long"}]}`, `{"id":1,"x":2}`, "unknown field x"},
+		{"nested path", `{"type":"record","name":"R","fields":[{"name":"ids","type":{"type":"array","items":"int"}}]}`, `{"ids":[1,"a"]}`, "ids: [1]: expected integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := toAvroNative(av.MustParse(tt.schema), decodeJSONValue(t, tt.input), AvroNatural)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("toAvroNative() error = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
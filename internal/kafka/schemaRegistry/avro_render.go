@@ -0,0 +1,304 @@
+package schemaRegistry
+
+import (
+	"encoding/binary"
+	"math/big"
+	"reflect"
+	"time"
+
+	av "github.com/hamba/avro/v2"
+)
+
+const (
+	// AvroNatural renders Avro values as plain JSON: unions unwrapped, decimals as strings,
+	// timestamps and dates in ISO 8601 form and bytes as base64
+	AvroNatural = "natural"
+	// AvroJSON renders Avro values in the JSON encoding of the Avro specification:
+	// unions wrapped as {"<type>": value}, logical types as their underlying type and bytes as ISO-8859-1 strings
+	AvroJSON = "avro-json"
+)
+
+// AvroRenderings lists all supported Avro renderings
+var AvroRenderings = []string{AvroNatural, AvroJSON}
+
+const (
+	localTimestampLayout = "2006-01-02T15:04:05.999999999"
+	timeMillisLayout     = "15:04:05.000"
+	timeMicrosLayout     = "15:04:05.000000"
+)
+
+// renderAvro converts a value decoded generically by hamba/avro into its JSON form for the rendering.
+// Values that do not match the schema are returned unchanged.
+func renderAvro(schema av.Schema, v interface{}, rendering string) interface{} {
+	if ref, ok := schema.(*av.RefSchema); ok {
+		schema = ref.Schema()
+	}
+	if v == nil {
+		return nil
+	}
+
+	switch s := schema.(type) {
+	case *av.PrimitiveSchema:
+		return renderPrimitive(s, v, rendering)
+	case *av.RecordSchema:
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		result := make(map[string]interface{}, len(values))
+		for name, value := range values {
+			if field := fieldByName(s, name); field != nil {
+				value = renderAvro(field.Type(), value, rendering)
+			}
+			result[name] = value
+		}
+		return result
+	case *av.ArraySchema:
+		items, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = renderAvro(s.Items(), item, rendering)
+		}
+		return result
+	case *av.MapSchema:
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		result := make(map[string]interface{}, len(values))
+		for key, value := range values {
+			result[key] = renderAvro(s.Values(), value, rendering)
+		}
+		return result
+	case *av.UnionSchema:
+		return renderUnion(s, v, rendering)
+	case *av.FixedSchema:
+		return renderFixed(s, v, rendering)
+	default:
+		return v
+	}
+}
+
+// renderUnion finds the branch of a union value. hamba/avro returns values of primitive branches as they are
+// and wraps all others as {"<branch name>": value}, see unionBranchName.
+func renderUnion(s *av.UnionSchema, v interface{}, rendering string) interface{} {
+	branch, value := decodedUnionBranch(s, v)
+	if branch == nil {
+		return v
+	}
+
+	rendered := renderAvro(branch, value, rendering)
+	if rendering != AvroJSON {
+		return rendered
+	}
+	return map[string]interface{}{avroJSONTypeName(branch): rendered}
+}
+
+// decodedUnionBranch finds the branch of a union value decoded by hamba/avro and unwraps the value if it is wrapped
+func decodedUnionBranch(s *av.UnionSchema, v interface{}) (av.Schema, interface{}) {
+	if wrapped, ok := v.(map[string]interface{}); ok && len(wrapped) == 1 {
+		for name, value := range wrapped {
+			for _, branch := range s.Types() {
+				if unionBranchName(branch) == name {
+					return branch, value
+				}
+			}
+		}
+	}
+
+	for _, branch := range s.Types() {
+		if primitive, ok := branch.(*av.PrimitiveSchema); ok && isDecodedAs(primitive, v) {
+			return branch, v
+		}
+	}
+	return nil, v
+}
+
+// isDecodedAs reports whether v has the Go type hamba/avro decodes the primitive type to
+func isDecodedAs(s *av.PrimitiveSchema, v interface{}) bool {
+	var logical av.LogicalType
+	if s.Logical() != nil {
+		logical = s.Logical().Type()
+	}
+
+	var ok bool
+	switch s.Type() {
+	case av.Boolean:
+		_, ok = v.(bool)
+	case av.String:
+		_, ok = v.(string)
+	case av.Int:
+		switch logical {
+		case av.Date:
+			_, ok = v.(time.Time)
+		case av.TimeMillis:
+			_, ok = v.(time.Duration)
+		default:
+			_, ok = v.(int)
+		}
+	case av.Long:
+		switch logical {
+		case av.TimestampMillis, av.TimestampMicros, av.LocalTimestampMillis, av.LocalTimestampMicros:
+			_, ok = v.(time.Time)
+		case av.TimeMicros:
+			_, ok = v.(time.Duration)
+		default:
+			_, ok = v.(int64)
+		}
+	case av.Float:
+		_, ok = v.(float32)
+	case av.Double:
+		_, ok = v.(float64)
+	case av.Bytes:
+		if logical == av.Decimal {
+			_, ok = v.(*big.Rat)
+		} else {
+			_, ok = v.([]byte)
+		}
+	}
+	return ok
+}
+
+// avroJSONTypeName is the name of a union branch in the Avro JSON encoding, logical types use their underlying type
+func avroJSONTypeName(schema av.Schema) string {
+	if ref, ok := schema.(*av.RefSchema); ok {
+		schema = ref.Schema()
+	}
+	if named, ok := schema.(av.NamedSchema); ok {
+		return named.FullName()
+	}
+	return string(schema.Type())
+}
+
+func renderPrimitive(s *av.PrimitiveSchema, v interface{}, rendering string) interface{} {
+	var logical av.LogicalType
+	if s.Logical() != nil {
+		logical = s.Logical().Type()
+	}
+
+	switch value := v.(type) {
+	case *big.Rat:
+		if decimal, ok := s.Logical().(*av.DecimalLogicalSchema); ok {
+			return renderDecimal(value, decimal.Scale(), 0, rendering)
+		}
+	case []byte:
+		if rendering == AvroJSON {
+			return latin1(value)
+		}
+	case time.Time:
+		return renderTime(value, logical, rendering)
+	case time.Duration:
+		return renderTimeOfDay(value, logical, rendering)
+	}
+	return v
+}
+
+func renderFixed(s *av.FixedSchema, v interface{}, rendering string) interface{} {
+	switch value := v.(type) {
+	case *big.Rat:
+		if decimal, ok := s.Logical().(*av.DecimalLogicalSchema); ok {
+			return renderDecimal(value, decimal.Scale(), s.Size(), rendering)
+		}
+	case av.LogicalDuration:
+		if rendering == AvroJSON {
+			data := make([]byte, 12)
+			binary.LittleEndian.PutUint32(data[0:], value.Months)
+			binary.LittleEndian.PutUint32(data[4:], value.Days)
+			binary.LittleEndian.PutUint32(data[8:], value.Milliseconds)
+			return latin1(data)
+		}
+		return map[string]interface{}{
+			"months":       value.Months,
+			"days":         value.Days,
+			"milliseconds": value.Milliseconds,
+		}
+	}
+
+	// Fixed values are decoded as byte arrays of the fixed size
+	array := reflect.ValueOf(v)
+	if array.Kind() != reflect.Array || array.Type().Elem().Kind() != reflect.Uint8 {
+		return v
+	}
+	data := make([]byte, array.Len())
+	reflect.Copy(reflect.ValueOf(data), array)
+	if rendering == AvroJSON {
+		return latin1(data)
+	}
+	return data
+}
+
+// renderDecimal renders a decimal with all digits of its scale, or as the two's complement of its unscaled value.
+// A size > 0 is the size of a fixed decimal, otherwise the minimal number of bytes is used.
+func renderDecimal(r *big.Rat, scale int, size int, rendering string) interface{} {
+	if rendering != AvroJSON {
+		return r.FloatString(scale)
+	}
+
+	unscaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	n := new(big.Int).Quo(unscaled.Num(), unscaled.Denom())
+
+	if size == 0 {
+		magnitude := n
+		if n.Sign() < 0 {
+			magnitude = new(big.Int).Not(n)
+		}
+		size = magnitude.BitLen()/8 + 1
+	}
+
+	modulus := new(big.Int).Lsh(big.NewInt(1), uint(size*8))
+	return latin1(new(big.Int).Mod(n, modulus).FillBytes(make([]byte, size)))
+}
+
+func renderTime(t time.Time, logical av.LogicalType, rendering string) interface{} {
+	t = t.UTC()
+	if rendering == AvroJSON {
+		switch logical {
+		case av.Date:
+			days := t.Unix() / 86400
+			if t.Unix()%86400 < 0 {
+				days--
+			}
+			return days
+		case av.TimestampMicros, av.LocalTimestampMicros:
+			return t.UnixMicro()
+		default:
+			return t.UnixMilli()
+		}
+	}
+
+	switch logical {
+	case av.Date:
+		return t.Format(time.DateOnly)
+	case av.LocalTimestampMillis, av.LocalTimestampMicros:
+		return t.Format(localTimestampLayout)
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+func renderTimeOfDay(d time.Duration, logical av.LogicalType, rendering string) interface{} {
+	if rendering == AvroJSON {
+		if logical == av.TimeMicros {
+			return d.Microseconds()
+		}
+		return d.Milliseconds()
+	}
+
+	layout := timeMillisLayout
+	if logical == av.TimeMicros {
+		layout = timeMicrosLayout
+	}
+	return time.Time{}.Add(d).Format(layout)
+}
+
+// latin1 encodes bytes as a string with one code point per byte, as in the Avro JSON encoding
+func latin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -0,0 +1,110 @@
+package schemaRegistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	av "github.com/hamba/avro/v2"
+)
+
+// normalizeJSON re-encodes JSON, so that equal values compare equal regardless of escaping
+func normalizeJSON(t *testing.T, input string) string {
+	t.Helper()
+	data, err := json.Marshal(decodeJSONValue(t, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRenderAvro(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	const inner = `{"type":"record","name":"Inner","namespace":"ns","fields":[{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":6,"scale":2}}]}`
+
+	tests := []struct {
+		name     string
+		schema   string
+		value    interface{}
+		natural  string
+		avroJSON string
+	}{
+		{
+			"nullable record",
+			`{"type":"record","name":"Outer","namespace":"ns","fields":[{"name":"inner","type":["null",` + inner + `]}]}`,
+			map[string]interface{}{"inner": map[string]interface{}{"ns.Inner": map[string]interface{}{"amount": big.NewRat(617, 50)}}},
+			`{"inner":{"amount":"12.34"}}`,
+			`{"inner":{"ns.Inner":{"amount":"\u0004Ò"}}}`,
+		},
+		{"nullable record null", `["null",` + inner + `]`, nil, `null`, `null`},
+		{"nullable string", `["null","string"]`, "a", `"a"`, `{"string":"a"}`},
+		{"multi-branch long", `["string","long"]`, int64(5), `5`, `{"long":5}`},
+		{"multi-branch string", `["null","int","string"]`, "x", `"x"`, `{"string":"x"}`},
+		{"multi-branch int", `["null","int","string"]`, 7, `7`, `{"int":7}`},
+		{"union double", `["int","double"]`, 1.5, `1.5`, `{"double":1.5}`},
+		{"nullable enum", `["null",{"type":"enum","name":"E","symbols":["A","B"]}]`, map[string]interface{}{"E": "B"}, `"B"`, `{"E":"B"}`},
+		{"nullable array", `["null",{"type":"array","items":"int"}]`, map[string]interface{}{"array": []interface{}{1, 2}}, `[1,2]`, `{"array":[1,2]}`},
+		{"nullable map", `["null",{"type":"map","values":"long"}]`, map[string]interface{}{"map": map[string]interface{}{"a": int64(1)}}, `{"a":1}`, `{"map":{"a":1}}`},
+		{"bytes", `"bytes"`, []byte("hi"), `"aGk="`, `"hi"`},
+		{"decimal", `{"type":"bytes","logicalType":"decimal","precision":6,"scale":2}`, big.NewRat(617, 50), `"12.34"`, `"\u0004Ò"`},
+		{"negative decimal", `{"type":"bytes","logicalType":"decimal","precision":6,"scale":2}`, big.NewRat(-1, 100), `"-0.01"`, `"ÿ"`},
+		{"nullable decimal", `["null",{"type":"bytes","logicalType":"decimal","precision":6,"scale":2}]`, big.NewRat(617, 50), `"12.34"`, `{"bytes":"\u0004Ò"}`},
+		{"fixed decimal", `{"type":"fixed","name":"D","size":4,"logicalType":"decimal","precision":6,"scale":2}`, big.NewRat(-617, 50), `"-12.34"`, `"ÿÿû."`},
+		{"fixed", `{"type":"fixed","name":"F","size":2}`, [2]byte{1, 255}, `"Af8="`, `"\u0001ÿ"`},
+		{"nullable fixed", `["null",{"type":"fixed","name":"F","namespace":"ns","size":2}]`, map[string]interface{}{"ns.F": [2]byte{1, 2}}, `"AQI="`, `{"ns.F":"\u0001\u0002"}`},
+		{
+			"duration",
+			`{"type":"fixed","name":"P","size":12,"logicalType":"duration"}`,
+			av.LogicalDuration{Months: 1, Days: 2, Milliseconds: 3},
+			`{"months":1,"days":2,"milliseconds":3}`,
+			`"\u0001\u0000\u0000\u0000\u0002\u0000\u0000\u0000\u0003\u0000\u0000\u0000"`,
+		},
+		{"timestamp-millis", `{"type":"long","logicalType":"timestamp-millis"}`, timestamp, `"2024-01-02T03:04:05.006Z"`, `1704164645006`},
+		{"timestamp-micros", `{"type":"long","logicalType":"timestamp-micros"}`, timestamp, `"2024-01-02T03:04:05.006Z"`, `1704164645006000`},
+		{"local-timestamp-millis", `{"type":"long","logicalType":"local-timestamp-millis"}`, timestamp, `"2024-01-02T03:04:05.006"`, `1704164645006`},
+		{"nullable timestamp", `["null",{"type":"long","logicalType":"timestamp-millis"}]`, timestamp, `"2024-01-02T03:04:05.006Z"`, `{"long":1704164645006}`},
+		{"date", `{"type":"int","logicalType":"date"}`, timestamp.Truncate(24 * time.Hour), `"2024-01-02"`, `19724`},
+		{"time-millis", `{"type":"int","logicalType":"time-millis"}`, 5007 * time.Millisecond, `"00:00:05.007"`, `5007`},
+		{"time-micros", `{"type":"long","logicalType":"time-micros"}`, 1500 * time.Microsecond, `"00:00:00.001500"`, `1500`},
+	}
+
+	for _, tt := range tests {
+		schema := av.MustParse(tt.schema)
+		data, err := av.Marshal(schema, tt.value)
+		if err != nil {
+			t.Fatalf("%s: invalid test value: %v", tt.name, err)
+		}
+
+		for rendering, want := range map[string]string{AvroNatural: tt.natural, AvroJSON: tt.avroJSON} {
+			t.Run(tt.name+"/"+rendering, func(t *testing.T) {
+				var decoded interface{}
+				if err := av.Unmarshal(schema, data, &decoded); err != nil {
+					t.Fatal(err)
+				}
+
+				rendered, err := json.Marshal(renderAvro(schema, decoded, rendering))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := normalizeJSON(t, string(rendered)); got != normalizeJSON(t, want) {
+					t.Fatalf("renderAvro() = %s, want %s", got, want)
+				}
+
+				// gokcat produce reads the rendered value with the same rendering
+				native, err := toAvroNative(schema, decodeJSONValue(t, string(rendered)), rendering)
+				if err != nil {
+					t.Fatalf("toAvroNative() error = %v", err)
+				}
+				encoded, err := av.Marshal(schema, native)
+				if err != nil {
+					t.Fatalf("hamba/avro rejects the converted value: %v", err)
+				}
+				if !bytes.Equal(encoded, data) {
+					t.Fatalf("round trip = %x, want %x", encoded, data)
+				}
+			})
+		}
+	}
+}
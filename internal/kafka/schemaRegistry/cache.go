@@ -0,0 +1,96 @@
+package schemaRegistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/philipparndt/go-logger"
+)
+
+// Schemas are immutable by ID, so they are cached on disk as returned by the Schema Registry:
+// <cacheDir>/ids/<id>.json and <cacheDir>/subjects/<subject>/<version>.json for numeric versions.
+
+func (c *Client) idCachePath(schemaID int) string {
+	return filepath.Join(c.CacheDir, "ids", strconv.Itoa(schemaID)+".json")
+}
+
+func (c *Client) subjectCachePath(subject string, version string) string {
+	if _, err := strconv.Atoi(version); err != nil {
+		// "latest" changes with every registered version
+		return ""
+	}
+	return filepath.Join(c.CacheDir, "subjects", url.PathEscape(subject), version+".json")
+}
+
+// isCached reports whether the schema with the given ID is in the disk cache
+func (c *Client) isCached(schemaID int) bool {
+	if c.CacheDir == "" {
+		return false
+	}
+	_, err := os.Stat(c.idCachePath(schemaID))
+	return err == nil
+}
+
+// cached fetches a response from the disk cache, or from the Schema Registry unless offline.
+// Fetched responses are stored in the cache.
+func (c *Client) cached(cachePath string, fetch func() error, result interface{}) error {
+	if c.CacheDir == "" || cachePath == "" {
+		if c.Offline {
+			return errors.New("only cached schemas by ID or version are available in offline mode")
+		}
+		return fetch()
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err == nil {
+		if err := json.Unmarshal(data, result); err == nil {
+			return nil
+		}
+		logger.Warn("Ignoring invalid schema cache file", cachePath)
+	}
+
+	if c.Offline {
+		return fmt.Errorf("not in the schema cache %s (offline mode)", c.CacheDir)
+	}
+
+	if err := fetch(); err != nil {
+		return err
+	}
+
+	if err := writeCacheFile(cachePath, result); err != nil {
+		logger.Warn("Failed to write schema cache", cachePath, err)
+	}
+	return nil
+}
+
+// writeCacheFile writes to a temporary file first, so that concurrent runs never read partial files
+func writeCacheFile(path string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
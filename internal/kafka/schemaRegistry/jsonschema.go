@@ -0,0 +1,87 @@
+package schemaRegistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const jsonSchemaBaseURL = "mem:///"
+
+// ValidationError reports a payload that could be decoded but does not match its schema
+type ValidationError struct {
+	SchemaID int
+	Err      error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("payload does not match schema %d: %v", e.SchemaID, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// parseJSONSchema reads the title of a JSON schema and compiles it with its references if validation is enabled
+func (d *Deserializer) parseJSONSchema(schemaText string, references []Reference) (Schema, error) {
+	schema := Schema{
+		SchemaType: TypeJSON,
+		Schema:     schemaText,
+	}
+
+	var doc struct {
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal([]byte(schemaText), &doc); err != nil {
+		return Schema{}, fmt.Errorf("failed to unmarshal JSON schema: %v", err)
+	}
+	schema.Name = doc.Title
+
+	if !d.ValidateJSON {
+		return schema, nil
+	}
+
+	files := map[string]string{"schema.json": schemaText}
+	if err := d.loadReferences(references, files); err != nil {
+		return Schema{}, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	for name, text := range files {
+		resource, err := jsonschema.UnmarshalJSON(strings.NewReader(text))
+		if err != nil {
+			return Schema{}, fmt.Errorf("failed to unmarshal JSON schema %s: %v", name, err)
+		}
+		if err := compiler.AddResource(jsonSchemaBaseURL+name, resource); err != nil {
+			return Schema{}, fmt.Errorf("failed to add JSON schema %s: %v", name, err)
+		}
+	}
+
+	compiled, err := compiler.Compile(jsonSchemaBaseURL + "schema.json")
+	if err != nil {
+		return Schema{}, fmt.Errorf("failed to compile JSON schema: %v", err)
+	}
+	schema.jsonSchema = compiled
+
+	return schema, nil
+}
+
+// deserializeJSON decodes a JSON payload. If the schema was compiled for validation,
+// the decoded payload is returned together with a *ValidationError when it does not match.
+func (d *Deserializer) deserializeJSON(schema *Schema, data []byte) (interface{}, error) {
+	result, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JSON data with schema %d: %v", schema.ID, err)
+	}
+
+	if schema.jsonSchema != nil {
+		if err := schema.jsonSchema.Validate(result); err != nil {
+			return result, &ValidationError{SchemaID: schema.ID, Err: err}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package schemaRegistry
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/philipparndt/go-logger"
+)
+
+// Keys written by e.g. a big-endian long serializer often look like Confluent framed data,
+// so framed keys are only decoded if their schema ID is known.
+
+// keySubjectIDs are the schema IDs of the key subject (<topic>-key) by topic
+var keySubjectIDs = make(map[string]map[int]bool)
+
+// IsKnownKeySchema reports whether framed key data uses a known schema ID: a local schema,
+// a schema loaded before, a cached schema or a version of the key subject of the topic (<topic>-key).
+func (d *Deserializer) IsKnownKeySchema(topic string, data []byte) bool {
+	if !IsFramed(data) {
+		return false
+	}
+
+	id := SchemaID(data)
+	if d.isKnownSchemaID(id) {
+		return true
+	}
+	return d.keySubjectIDs(topic)[id]
+}
+
+// keySubjectIDs loads the schema IDs of all versions of the key subject once per topic
+func (d *Deserializer) keySubjectIDs(topic string) map[int]bool {
+	if ids, ok := keySubjectIDs[topic]; ok {
+		return ids
+	}
+
+	ids := make(map[int]bool)
+	keySubjectIDs[topic] = ids
+	if d.client.Offline {
+		return ids
+	}
+
+	subject := topic + "-key"
+	versions, err := d.client.GetVersions(subject)
+	if err != nil {
+		var registryErr *RegistryError
+		if !errors.As(err, &registryErr) || registryErr.ErrorCode != errorCodeSubjectNotFound {
+			logger.Warn("Failed to get the versions of subject", subject, "keys are only decoded with known schemas", err)
+		}
+		return ids
+	}
+
+	for _, version := range versions {
+		resp, err := d.client.GetSchemaBySubjectVersion(subject, strconv.Itoa(version))
+		if err != nil {
+			logger.Warn("Failed to get schema of subject", subject, "version", strconv.Itoa(version), err)
+			continue
+		}
+		ids[resp.ID] = true
+	}
+	return ids
+}
@@ -0,0 +1,105 @@
+package schemaRegistry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	av "github.com/hamba/avro/v2"
+)
+
+// LoadSchemaFile parses a local Avro schema file (.avsc). The schema has ID 0 unless it is mapped with AddSchemaFile.
+func (d *Deserializer) LoadSchemaFile(file string) (*Schema, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	schema, err := d.parseSchema(TypeAvro, string(data), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema file %s: %v", file, err)
+	}
+	return &schema, nil
+}
+
+// AddSchemaFile maps a schema ID to a local Avro schema file. LoadSchemaInfo uses it instead of the Schema Registry.
+func (d *Deserializer) AddSchemaFile(id int, file string) error {
+	schema, err := d.LoadSchemaFile(file)
+	if err != nil {
+		return err
+	}
+
+	schema.ID = id
+	if d.localSchemas == nil {
+		d.localSchemas = make(map[int]*Schema)
+	}
+	d.localSchemas[id] = schema
+	return nil
+}
+
+// DeserializeUnframed decodes data with the given schema, skipping the Confluent header if the data has one
+func (d *Deserializer) DeserializeUnframed(schema *Schema, data []byte) (interface{}, error) {
+	return d.DeserializeUnframedAs(nil, schema, data)
+}
+
+// DeserializeUnframedAs is DeserializeUnframed with an optional reader schema, see DeserializeAs.
+// Plain Avro may start with the magic byte too, so data is only taken as framed if its schema ID is known
+// (see AddSchemaFile) or if it does not decode as plain Avro. Either way the data must be consumed completely.
+func (d *Deserializer) DeserializeUnframedAs(reader *Schema, schema *Schema, data []byte) (interface{}, error) {
+	exact := *d
+	exact.exact = true
+
+	if IsFramed(data) && d.isKnownSchemaID(SchemaID(data)) {
+		return exact.DeserializeAs(reader, schema, data[5:])
+	}
+
+	payload, err := exact.DeserializeAs(reader, schema, data)
+	var resolutionErr *ResolutionError
+	if err != nil && !errors.As(err, &resolutionErr) && IsFramed(data) {
+		framed, framedErr := exact.DeserializeAs(reader, schema, data[5:])
+		if framedErr == nil || errors.As(framedErr, &resolutionErr) {
+			return framed, framedErr
+		}
+	}
+	return payload, err
+}
+
+// isKnownSchemaID reports whether a schema ID is mapped to a local file or was loaded from the Schema Registry before
+func (d *Deserializer) isKnownSchemaID(id int) bool {
+	if d.localSchemas[id] != nil || d.client.isCached(id) {
+		return true
+	}
+	for key := range schemaInfoCache {
+		if key.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// unmarshalAvro decodes Avro data, rejecting trailing bytes if the deserializer is exact
+func (d *Deserializer) unmarshalAvro(schema av.Schema, data []byte, v interface{}) error {
+	if !d.exact {
+		return av.Unmarshal(schema, data, v)
+	}
+
+	reader := av.NewReader(nil, 0).Reset(data)
+	reader.ReadVal(schema, v)
+	if errors.Is(reader.Error, io.EOF) {
+		return fmt.Errorf("data is truncated: %w", io.ErrUnexpectedEOF)
+	}
+	if reader.Error != nil {
+		return reader.Error
+	}
+
+	// Only running out of data after the value means all data is consumed
+	reader.Peek()
+	if reader.Error == nil {
+		return errors.New("data has trailing bytes")
+	}
+	if !errors.Is(reader.Error, io.EOF) {
+		return reader.Error
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package schemaRegistry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadTestSchema loads an Avro schema through a local schema file
+func loadTestSchema(t *testing.T, d *Deserializer, schema string) *Schema {
+	t.Helper()
+	s, err := d.LoadSchemaFile(writeTestSchemaFile(t, schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDeserializeUnframed(t *testing.T) {
+	const record = `{"type":"record","name":"R","fields":[{"name":"a","type":"int"},{"name":"items","type":{"type":"array","items":"int"}}]}`
+
+	tests := []struct {
+		name   string
+		schema string
+		data   []byte
+		want   interface{}
+	}{
+		// a=0, items=[1,2,3,4], the first five bytes look like a header and the rest decodes as {a:4, items:[]}
+		{"plain starting with magic byte", record, []byte{0x00, 0x08, 0x02, 0x04, 0x06, 0x08, 0x00}, map[string]interface{}{"a": 0, "items": []interface{}{1, 2, 3, 4}}},
+		{"framed with mapped ID", `"int"`, []byte{0x00, 0x00, 0x00, 0x00, 0x07, 0x02}, 1},
+		{"framed with unknown ID", `"string"`, []byte{0x00, 0x00, 0x00, 0x00, 0x2a, 0x04, 'h', 'i'}, "hi"},
+		{"plain", `"string"`, []byte{0x04, 'h', 'i'}, "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Client{}.NewDeserializer()
+			if err := d.AddSchemaFile(7, writeTestSchemaFile(t, `"int"`)); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := d.DeserializeUnframed(loadTestSchema(t, &d, tt.schema), tt.data)
+			if err != nil {
+				t.Fatalf("DeserializeUnframed() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("DeserializeUnframed() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeserializeUnframedErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		data   []byte
+		want   string
+	}{
+		{"trailing bytes", `"int"`, []byte{0x02, 0x02}, "trailing bytes"},
+		{"truncated record", `{"type":"record","name":"R","fields":[{"name":"id","type":"long"},{"name":"name","type":"string"}]}`, []byte{0x02}, "truncated"},
+		{"truncated array", `{"type":"array","items":"int"}`, []byte{0x08, 0x02}, "truncated"},
+		{"no data", `{"type":"record","name":"R","fields":[{"name":"id","type":"long"}]}`, nil, "truncated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Client{}.NewDeserializer()
+
+			got, err := d.DeserializeUnframed(loadTestSchema(t, &d, tt.schema), tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("DeserializeUnframed() = %#v, error = %v, want error containing %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestSchemaFile writes a schema to a temporary file and returns its path
+func writeTestSchemaFile(t *testing.T, schema string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "schema.avsc")
+	if err := os.WriteFile(file, []byte(schema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
@@ -0,0 +1,142 @@
+package schemaRegistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const protobufSchemaFile = "schema.proto"
+
+// parseProtobufSchema compiles a .proto schema together with all the schemas it references
+func (d *Deserializer) parseProtobufSchema(schemaText string, references []Reference) (Schema, error) {
+	files := map[string]string{protobufSchemaFile: schemaText}
+	if err := d.loadReferences(references, files); err != nil {
+		return Schema{}, err
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(files),
+		}),
+	}
+
+	compiled, err := compiler.Compile(context.Background(), protobufSchemaFile)
+	if err != nil {
+		return Schema{}, fmt.Errorf("failed to compile protobuf schema: %v", err)
+	}
+
+	fd := compiled[0]
+	schema := Schema{
+		SchemaType: TypeProtobuf,
+		Namespace:  string(fd.Package()),
+		Schema:     schemaText,
+		protobuf:   fd,
+	}
+
+	if fd.Messages().Len() > 0 {
+		schema.Name = string(fd.Messages().Get(0).Name())
+	}
+
+	return schema, nil
+}
+
+// loadReferences fetches all referenced schemas recursively, keyed by their import name
+func (d *Deserializer) loadReferences(references []Reference, files map[string]string) error {
+	for _, ref := range references {
+		if _, ok := files[ref.Name]; ok {
+			continue
+		}
+
+		resp, err := d.client.GetSchemaBySubjectVersion(ref.Subject, strconv.Itoa(ref.Version))
+		if err != nil {
+			return fmt.Errorf("failed to get referenced schema %s (subject %s, version %d): %v", ref.Name, ref.Subject, ref.Version, err)
+		}
+
+		files[ref.Name] = resp.Schema
+		if err := d.loadReferences(resp.References, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Deserializer) deserializeProtobuf(schema *Schema, data []byte) (interface{}, error) {
+	indexes, data, err := readMessageIndexes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := findMessage(schema.protobuf, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode protobuf message %s with schema %d: %v", md.FullName(), schema.ID, err)
+	}
+
+	jsonData, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert protobuf message %s to JSON: %v", md.FullName(), err)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(jsonData, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal protobuf JSON: %v", err)
+	}
+
+	return result, nil
+}
+
+// readMessageIndexes reads the zigzag varint encoded message indexes that follow the Confluent header.
+// A single 0 is a shortcut for the first message in the schema.
+func readMessageIndexes(data []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 {
+		return nil, nil, fmt.Errorf("failed to read protobuf message index count")
+	}
+	data = data[n:]
+
+	if count == 0 {
+		return []int{0}, data, nil
+	}
+	if count < 0 || count > int64(len(data)) {
+		return nil, nil, fmt.Errorf("invalid protobuf message index count %d", count)
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		index, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, nil, fmt.Errorf("failed to read protobuf message index")
+		}
+		indexes[i] = int(index)
+		data = data[n:]
+	}
+
+	return indexes, data, nil
+}
+
+// findMessage resolves the message descriptor for a path of message indexes, e.g. [1, 0] is the first nested message of the second message
+func findMessage(fd protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	var md protoreflect.MessageDescriptor
+	messages := fd.Messages()
+	for _, index := range indexes {
+		if index < 0 || index >= messages.Len() {
+			return nil, fmt.Errorf("protobuf message index %v not found in schema", indexes)
+		}
+		md = messages.Get(index)
+		messages = md.Messages()
+	}
+	return md, nil
+}
@@ -0,0 +1,108 @@
+package schemaRegistry
+
+import (
+	"errors"
+	"fmt"
+
+	av "github.com/hamba/avro/v2"
+)
+
+// ResolutionError reports data that cannot be read with the reader schema, see DeserializeAs
+type ResolutionError struct {
+	WriterID int
+	Err      error
+}
+
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("cannot resolve writer schema %d to the reader schema: %v", e.WriterID, e.Err)
+}
+
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
+}
+
+type resolvedKey struct {
+	reader *Schema
+	writer *Schema
+}
+
+type resolvedSchema struct {
+	schema av.Schema
+	err    error
+}
+
+var resolvedCache = make(map[resolvedKey]resolvedSchema)
+
+// LoadSubjectSchema loads a schema by its subject and version, the version can be "latest"
+func (d *Deserializer) LoadSubjectSchema(subject string, version string) (*Schema, error) {
+	resp, err := d.client.GetSchemaBySubjectVersion(subject, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema of subject %s version %s: %v", subject, version, err)
+	}
+
+	schema, err := d.parseSchema(resp.SchemaType, resp.Schema, resp.References)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema %d: %v", resp.ID, err)
+	}
+
+	schema.ID = resp.ID
+	return &schema, nil
+}
+
+// DeserializeAs decodes data written with the writer schema into the shape of the Avro reader schema,
+// using Avro schema resolution. Without a reader schema it is the same as Deserialize.
+// On a *ResolutionError the payload decoded with the writer schema is returned as well.
+func (d *Deserializer) DeserializeAs(reader *Schema, writer *Schema, data []byte) (interface{}, error) {
+	if reader == nil || reader == writer {
+		return d.Deserialize(writer, data)
+	}
+
+	resolved, err := resolve(reader, writer)
+	if err != nil {
+		payload, decodeErr := d.Deserialize(writer, data)
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		return payload, &ResolutionError{WriterID: writer.ID, Err: err}
+	}
+
+	var result interface{}
+	if err := d.unmarshalAvro(resolved, data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode Avro data with schema %d: %v", writer.ID, err)
+	}
+	return renderAvro(reader.avro, result, d.AvroRendering), nil
+}
+
+// resolve returns the schema reading data of the writer schema as the reader schema, results are cached
+func resolve(reader *Schema, writer *Schema) (av.Schema, error) {
+	key := resolvedKey{reader: reader, writer: writer}
+	if cached, ok := resolvedCache[key]; ok {
+		return cached.schema, cached.err
+	}
+
+	var result resolvedSchema
+	if reader.SchemaType != TypeAvro || writer.SchemaType != TypeAvro {
+		result.err = errors.New("reader schemas are only supported for Avro")
+	} else if readerAvro, err := avroSchema(reader); err != nil {
+		result.err = err
+	} else if writerAvro, err := avroSchema(writer); err != nil {
+		result.err = err
+	} else {
+		result.schema, result.err = av.NewSchemaCompatibility().Resolve(readerAvro, writerAvro)
+	}
+
+	resolvedCache[key] = result
+	return result.schema, result.err
+}
+
+// avroSchema returns the parsed Avro schema, parsing it on first use
+func avroSchema(schema *Schema) (av.Schema, error) {
+	if schema.avro == nil {
+		s, err := av.Parse(schema.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Avro schema %d: %v", schema.ID, err)
+		}
+		schema.avro = s
+	}
+	return schema.avro, nil
+}
@@ -1,15 +1,17 @@
 package schemaRegistry
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
-	"github.com/IBM/sarama"
-	av "github.com/hamba/avro/v2"
 	"github.com/philipparndt/go-logger"
 )
 
@@ -18,15 +20,22 @@ type Client struct {
 	username   string
 	password   string
 	httpClient *http.Client
+	// CacheDir enables the on-disk cache of schemas by ID and subject version if set
+	CacheDir string
+	// Offline only uses the on-disk cache and never contacts the Schema Registry for schemas
+	Offline bool
 }
 
 type Deserializer struct {
 	client *Client
-}
-
-type SchemaResponse struct {
-	Schema string `json:"schema"`
-	ID     int    `json:"id"`
+	// ValidateJSON enables validation of JSON Schema framed payloads against their schema
+	ValidateJSON bool
+	// AvroRendering is the JSON form of decoded Avro values, one of AvroRenderings (default AvroNatural)
+	AvroRendering string
+	// localSchemas are schemas loaded from files by ID, see AddSchemaFile
+	localSchemas map[int]*Schema
+	// exact rejects Avro data that is not consumed completely, see DeserializeUnframedAs
+	exact bool
 }
 
 func New(url string, username string, password string, insecure bool) Client {
@@ -58,13 +67,90 @@ func (c Client) NewDeserializer() Deserializer {
 	}
 }
 
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+type SchemaResponse struct {
+	Schema     string      `json:"schema"`
+	ID         int         `json:"id"`
+	SchemaType string      `json:"schemaType,omitempty"`
+	References []Reference `json:"references,omitempty"`
+}
+
+type SubjectVersionResponse struct {
+	Subject    string      `json:"subject"`
+	Version    int         `json:"version"`
+	ID         int         `json:"id"`
+	Schema     string      `json:"schema"`
+	SchemaType string      `json:"schemaType,omitempty"`
+	References []Reference `json:"references,omitempty"`
+}
+
 // GetSchemaByID fetches a schema from the Schema Registry by its ID
 func (c *Client) GetSchemaByID(schemaID int) (*SchemaResponse, error) {
-	url := fmt.Sprintf("%s/schemas/ids/%d", c.url, schemaID)
+	var schemaResp SchemaResponse
+	err := c.cached(c.idCachePath(schemaID), func() error {
+		return c.get(fmt.Sprintf("/schemas/ids/%d", schemaID), &schemaResp)
+	}, &schemaResp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemaResp, nil
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// GetSchemaBySubjectVersion fetches a schema from the Schema Registry by its subject and version
+func (c *Client) GetSchemaBySubjectVersion(subject string, version string) (*SubjectVersionResponse, error) {
+	var versionResp SubjectVersionResponse
+	err := c.cached(c.subjectCachePath(subject, version), func() error {
+		return c.get(fmt.Sprintf("/subjects/%s/versions/%s", url.PathEscape(subject), url.PathEscape(version)), &versionResp)
+	}, &versionResp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
+	}
+
+	return &versionResp, nil
+}
+
+// get performs a GET request against the Schema Registry and unmarshals the JSON response into result
+func (c *Client) get(path string, result interface{}) error {
+	return c.do("GET", path, nil, result)
+}
+
+// post sends the body as JSON to the Schema Registry and unmarshals the JSON response into result
+func (c *Client) post(path string, body interface{}, result interface{}) error {
+	return c.do("POST", path, body, result)
+}
+
+// RegistryError is returned if the Schema Registry responds with an error status
+type RegistryError struct {
+	StatusCode int
+	// ErrorCode is the error_code of the response, e.g. 40401 if a subject was not found
+	ErrorCode int
+	Message   string
+	body      string
+}
+
+func (e *RegistryError) Error() string {
+	return fmt.Sprintf("schema registry returned status %d: %s", e.StatusCode, e.body)
+}
+
+func (c *Client) do(method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.url+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
 	}
 
 	// Add Basic Auth if credentials are provided
@@ -72,30 +158,38 @@ func (c *Client) GetSchemaByID(schemaID int) (*SchemaResponse, error) {
 		req.SetBasicAuth(c.username, c.password)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %v", err)
+		return fmt.Errorf("failed to make request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("schema registry returned status %d: %s", resp.StatusCode, string(body))
+		registryErr := &RegistryError{StatusCode: resp.StatusCode, body: string(body)}
+		var details struct {
+			ErrorCode int    `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		if json.Unmarshal(body, &details) == nil {
+			registryErr.ErrorCode = details.ErrorCode
+			registryErr.Message = details.Message
+		}
+		return registryErr
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return fmt.Errorf("failed to read response body: %v", err)
 	}
 
-	var schemaResp SchemaResponse
-	if err := json.Unmarshal(body, &schemaResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal schema response: %v", err)
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to unmarshal schema registry response: %v", err)
 	}
 
-	return &schemaResp, nil
+	return nil
 }
 
 type schemaInfoKey struct {
@@ -105,35 +199,58 @@ type schemaInfoKey struct {
 
 var schemaInfoCache = make(map[schemaInfoKey]*Schema)
 
-func (d *Deserializer) LoadSchemaInfo(topic string, msg *sarama.ConsumerMessage) (*Schema, error) {
+// failedSchemaIDs are the errors of schema IDs that are unknown or cannot be parsed, they are not retried during a run
+var failedSchemaIDs = make(map[int]error)
+
+// IsFramed reports whether the data starts with the Confluent wire format header (magic byte 0x0 and 4-byte schema ID)
+func IsFramed(data []byte) bool {
+	return len(data) >= 5 && data[0] == 0x0
+}
+
+// LoadSchemaInfo loads the schema of Confluent framed data, this can be a message value or key
+func (d *Deserializer) LoadSchemaInfo(topic string, data []byte) (*Schema, error) {
 	// Extract schema ID from the Avro message (first 5 bytes: magic byte + 4-byte schema ID)
-	if len(msg.Value) < 5 {
+	if len(data) < 5 {
 		return nil, fmt.Errorf("message too short to contain schema ID")
 	}
 
-	// Skip magic byte (first byte) and extract schema ID (next 4 bytes, big-endian)
-	id := (uint32(msg.Value[1]) << 24) | (uint32(msg.Value[2]) << 16) | (uint32(msg.Value[3]) << 8) | uint32(msg.Value[4])
+	id := SchemaID(data)
+
+	if schema := d.localSchemas[id]; schema != nil {
+		return schema, nil
+	}
 
 	key := schemaInfoKey{
 		topic: topic,
-		id:    int(id),
+		id:    id,
 	}
 
 	schema := schemaInfoCache[key]
 
 	if schema == nil {
+		// Failed IDs are not requested again, e.g. keys that only look like framed data
+		if err := failedSchemaIDs[id]; err != nil {
+			return nil, err
+		}
+
 		// Fetch schema from Schema Registry using REST API
-		schemaResp, err := d.client.GetSchemaByID(int(id))
+		schemaResp, err := d.client.GetSchemaByID(id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get schema by ID %d: %v", id, err)
+			err = fmt.Errorf("failed to get schema by ID %d: %w", id, err)
+			// Other errors like timeouts are retried with the next message
+			if isSchemaNotFound(err) {
+				failedSchemaIDs[id] = err
+			}
+			return nil, err
 		}
 
-		s, err := DeserializeSchema(schemaResp.Schema)
+		s, err := d.parseSchema(schemaResp.SchemaType, schemaResp.Schema, schemaResp.References)
 		if err != nil {
-			return nil, fmt.Errorf("failed to deserialize schema: %v", err)
+			failedSchemaIDs[id] = fmt.Errorf("failed to deserialize schema %d: %v", id, err)
+			return nil, failedSchemaIDs[id]
 		}
 
-		s.ID = int(id)
+		s.ID = id
 		schema = &s
 
 		schemaInfoCache[key] = schema
@@ -142,24 +259,67 @@ func (d *Deserializer) LoadSchemaInfo(topic string, msg *sarama.ConsumerMessage)
 	return schema, nil
 }
 
-func (d *Deserializer) Deserialize(schema *Schema, avroData []byte) map[string]interface{} {
+// isSchemaNotFound reports whether the Schema Registry does not know the requested schema
+func isSchemaNotFound(err error) bool {
+	var registryErr *RegistryError
+	return errors.As(err, &registryErr) && (registryErr.StatusCode == http.StatusNotFound || registryErr.ErrorCode == errorCodeSchemaNotFound)
+}
+
+// SchemaID returns the schema ID of Confluent framed data, see IsFramed
+func SchemaID(data []byte) int {
+	// Skip magic byte (first byte) and extract schema ID (next 4 bytes, big-endian)
+	return int(binary.BigEndian.Uint32(data[1:5]))
+}
+
+// parseSchema parses a schema of the given type as returned by the Schema Registry
+func (d *Deserializer) parseSchema(schemaType string, schemaText string, references []Reference) (Schema, error) {
+	switch schemaType {
+	case "", TypeAvro:
+		s, err := DeserializeSchema(schemaText)
+		if err != nil {
+			return Schema{}, err
+		}
+		s.SchemaType = TypeAvro
+		return s, nil
+	case TypeProtobuf:
+		return d.parseProtobufSchema(schemaText, references)
+	case TypeJSON:
+		return d.parseJSONSchema(schemaText, references)
+	default:
+		return Schema{}, fmt.Errorf("unsupported schema type %s", schemaType)
+	}
+}
+
+// Deserialize decodes the payload following the 5-byte Confluent header.
+// A *ValidationError is returned together with the decoded payload if it does not match its JSON schema.
+func (d *Deserializer) Deserialize(schema *Schema, data []byte) (interface{}, error) {
+	switch schema.SchemaType {
+	case TypeAvro:
+		return d.deserializeAvro(schema, data)
+	case TypeProtobuf:
+		return d.deserializeProtobuf(schema, data)
+	case TypeJSON:
+		return d.deserializeJSON(schema, data)
+	default:
+		return nil, fmt.Errorf("unsupported schema type %s", schema.SchemaType)
+	}
+}
+
+func (d *Deserializer) deserializeAvro(schema *Schema, avroData []byte) (interface{}, error) {
 	// Create Avro schema object
-	s, err := av.Parse(schema.Schema)
+	parsed, err := avroSchema(schema)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	// To decode generically, use a variable of type interface{}
 	var result interface{}
 
 	// Decode binary Avro data into result
-	err = av.Unmarshal(s, avroData, &result)
+	err = d.unmarshalAvro(parsed, avroData, &result)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to decode Avro data with schema %d: %v", schema.ID, err)
 	}
 
-	// To access fields:
-	m := result.(map[string]interface{})
-
-	return m
+	return renderAvro(parsed, result, d.AvroRendering), nil
 }
@@ -0,0 +1,61 @@
+package schemaRegistry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// framed returns Confluent framed data with the schema ID and an empty payload
+func framed(id byte) []byte {
+	return []byte{0x00, 0x00, 0x00, 0x00, id}
+}
+
+func TestLoadSchemaInfoRetries(t *testing.T) {
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/schemas/ids/101":
+			// unavailable on the first request only
+			if requests[r.URL.Path] == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"schema": "\"string\""}`))
+		case "/schemas/ids/102":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code": 40403, "message": "Schema 102 not found"}`))
+		case "/schemas/ids/103":
+			_, _ = w.Write([]byte(`{"schemaType": "XML", "schema": "<schema/>"}`))
+		}
+	}))
+	defer server.Close()
+
+	d := New(server.URL, "", "", false).NewDeserializer()
+
+	tests := []struct {
+		name     string
+		id       byte
+		wantErr  []bool
+		requests int
+	}{
+		{"transient error is retried", 101, []bool{true, false, false}, 2},
+		{"unknown schema is not retried", 102, []bool{true, true}, 1},
+		{"unsupported schema is not retried", 103, []bool{true, true}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, wantErr := range tt.wantErr {
+				if _, err := d.LoadSchemaInfo("topic", framed(tt.id)); (err != nil) != wantErr {
+					t.Fatalf("LoadSchemaInfo() call %d error = %v, want error %v", i, err, wantErr)
+				}
+			}
+			if got := requests[fmt.Sprintf("/schemas/ids/%d", tt.id)]; got != tt.requests {
+				t.Fatalf("requests = %d, want %d", got, tt.requests)
+			}
+		})
+	}
+}
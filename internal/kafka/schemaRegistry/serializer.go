@@ -0,0 +1,108 @@
+package schemaRegistry
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	av "github.com/hamba/avro/v2"
+)
+
+type Serializer struct {
+	parser Deserializer
+	cache  map[int]*Schema
+	// AvroRendering is the JSON form of the Avro values to serialize, one of AvroRenderings (default AvroNatural)
+	AvroRendering string
+}
+
+func (c Client) NewSerializer() Serializer {
+	return Serializer{
+		parser: c.NewDeserializer(),
+		cache:  make(map[int]*Schema),
+	}
+}
+
+// SchemaByID loads a schema by its ID
+func (s *Serializer) SchemaByID(id int) (*Schema, error) {
+	if schema := s.cache[id]; schema != nil {
+		return schema, nil
+	}
+
+	resp, err := s.parser.client.GetSchemaByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema by ID %d: %v", id, err)
+	}
+
+	return s.parse(id, resp.SchemaType, resp.Schema, resp.References)
+}
+
+// SchemaBySubject loads a schema by its subject and version, the version can be "latest"
+func (s *Serializer) SchemaBySubject(subject string, version string) (*Schema, error) {
+	resp, err := s.parser.client.GetSchemaBySubjectVersion(subject, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema of subject %s version %s: %v", subject, version, err)
+	}
+
+	if schema := s.cache[resp.ID]; schema != nil {
+		return schema, nil
+	}
+
+	return s.parse(resp.ID, resp.SchemaType, resp.Schema, resp.References)
+}
+
+func (s *Serializer) parse(id int, schemaType string, schemaText string, references []Reference) (*Schema, error) {
+	parsed, err := s.parser.parseSchema(schemaType, schemaText, references)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema %d: %v", id, err)
+	}
+
+	parsed.ID = id
+	s.cache[id] = &parsed
+	return &parsed, nil
+}
+
+// Serialize encodes a generic JSON value with the schema and prefixes it with the Confluent header
+func (s *Serializer) Serialize(schema *Schema, value interface{}) ([]byte, error) {
+	var payload []byte
+	switch schema.SchemaType {
+	case TypeAvro:
+		data, err := serializeAvro(schema, value, s.AvroRendering)
+		if err != nil {
+			return nil, err
+		}
+		payload = data
+	case TypeJSON:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode JSON with schema %d: %v", schema.ID, err)
+		}
+		payload = data
+	default:
+		return nil, fmt.Errorf("serializing %s schemas is not supported", schema.SchemaType)
+	}
+
+	header := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(header[1:], uint32(schema.ID))
+	return append(header, payload...), nil
+}
+
+func serializeAvro(schema *Schema, value interface{}, rendering string) ([]byte, error) {
+	if schema.avro == nil {
+		s, err := av.Parse(schema.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Avro schema %d: %v", schema.ID, err)
+		}
+		schema.avro = s
+	}
+
+	native, err := toAvroNative(schema.avro, value, rendering)
+	if err != nil {
+		return nil, fmt.Errorf("value does not match Avro schema %d: %v", schema.ID, err)
+	}
+
+	data, err := av.Marshal(schema.avro, native)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Avro data with schema %d: %v", schema.ID, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,109 @@
+package schemaRegistry
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// errorCodeSubjectNotFound is the error_code of the Schema Registry for unknown subjects
+const errorCodeSubjectNotFound = 40401
+
+// errorCodeSchemaNotFound is the error_code of the Schema Registry for unknown schema IDs
+const errorCodeSchemaNotFound = 40403
+
+type SubjectVersion struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+type compatibilityResponse struct {
+	CompatibilityLevel string `json:"compatibilityLevel"`
+}
+
+// GetSubjects lists all subjects of the Schema Registry
+func (c *Client) GetSubjects() ([]string, error) {
+	var subjects []string
+	if err := c.get("/subjects", &subjects); err != nil {
+		return nil, err
+	}
+	return subjects, nil
+}
+
+// GetVersions lists the versions of a subject
+func (c *Client) GetVersions(subject string) ([]int, error) {
+	var versions []int
+	if err := c.get(fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject)), &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetSubjectVersionsByID lists the subjects and versions that use the schema with the given ID
+func (c *Client) GetSubjectVersionsByID(schemaID int) ([]SubjectVersion, error) {
+	var usages []SubjectVersion
+	if err := c.get(fmt.Sprintf("/schemas/ids/%d/versions", schemaID), &usages); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
+
+// GetCompatibility returns the compatibility level of a subject, falling back to the global level.
+// Without a subject the global level is returned.
+func (c *Client) GetCompatibility(subject string) (string, error) {
+	path := "/config"
+	if subject != "" {
+		path = fmt.Sprintf("/config/%s?defaultToGlobal=true", url.PathEscape(subject))
+	}
+
+	var resp compatibilityResponse
+	if err := c.get(path, &resp); err != nil {
+		return "", err
+	}
+	return resp.CompatibilityLevel, nil
+}
+
+// SchemaRequest is a schema to register or to check, SchemaType is empty for Avro
+type SchemaRequest struct {
+	Schema     string      `json:"schema"`
+	SchemaType string      `json:"schemaType,omitempty"`
+	References []Reference `json:"references,omitempty"`
+}
+
+// CompatibilityResult is the result of a compatibility check, Messages explain why a schema is incompatible
+type CompatibilityResult struct {
+	IsCompatible bool     `json:"is_compatible"`
+	Messages     []string `json:"messages,omitempty"`
+}
+
+type registerResponse struct {
+	ID int `json:"id"`
+}
+
+// RegisterSchema registers the schema under the subject and returns its ID.
+// Registering a schema that already exists returns the existing ID.
+func (c *Client) RegisterSchema(subject string, schema SchemaRequest) (int, error) {
+	var resp registerResponse
+	if err := c.post(fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject)), schema, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// CheckCompatibility checks the schema against a version of the subject, the version can be "latest".
+// A subject without any versions accepts every schema.
+func (c *Client) CheckCompatibility(subject string, version string, schema SchemaRequest) (*CompatibilityResult, error) {
+	path := fmt.Sprintf("/compatibility/subjects/%s/versions/%s?verbose=true", url.PathEscape(subject), url.PathEscape(version))
+
+	var result CompatibilityResult
+	err := c.post(path, schema, &result)
+
+	var registryErr *RegistryError
+	if errors.As(err, &registryErr) && registryErr.ErrorCode == errorCodeSubjectNotFound {
+		return &CompatibilityResult{IsCompatible: true, Messages: []string{"subject " + subject + " does not exist yet"}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -3,7 +3,11 @@ package schemaRegistry
 import (
 	"encoding/json"
 	"fmt"
+	av "github.com/hamba/avro/v2"
 	"github.com/philipparndt/go-logger"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"strings"
 )
 
 type SchemaInfo struct {
@@ -11,21 +15,49 @@ type SchemaInfo struct {
 	// Include other fields as necessary
 }
 
+const (
+	TypeAvro     = "AVRO"
+	TypeProtobuf = "PROTOBUF"
+	TypeJSON     = "JSON"
+)
+
 type Schema struct {
-	ID        int    `json:"id"`
-	Type      string `json:"type"`
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
-	Fields    []struct {
+	ID         int    `json:"id"`
+	SchemaType string `json:"-"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Fields     []struct {
 		Name    string      `json:"name"`
 		Type    interface{} `json:"type"`
 		Default interface{} `json:"default"`
 	} `json:"fields"`
 	Schema string `json:"schema"`
+
+	avro       av.Schema
+	protobuf   protoreflect.FileDescriptor
+	jsonSchema *jsonschema.Schema
 }
 
 func DeserializeSchema(schemaJSON string) (Schema, error) {
 	var schema Schema
+
+	// Primitive schemas like "string" and unions are not JSON objects
+	switch trimmed := strings.TrimSpace(schemaJSON); {
+	case strings.HasPrefix(trimmed, `"`):
+		var primitive string
+		if err := json.Unmarshal([]byte(trimmed), &primitive); err != nil {
+			return Schema{}, fmt.Errorf("failed to unmarshal schema: %v", err)
+		}
+		schema.Type = primitive
+		schema.Schema = schemaJSON
+		return schema, nil
+	case strings.HasPrefix(trimmed, "["):
+		schema.Type = "union"
+		schema.Schema = schemaJSON
+		return schema, nil
+	}
+
 	err := json.Unmarshal([]byte(schemaJSON), &schema)
 	if err != nil {
 		logger.Error("Failed to unmarshal schema JSON", "error", err, "schemaJSON", schemaJSON)
@@ -10,10 +10,24 @@ import (
 	"github.com/philipparndt/go-logger"
 )
 
+// UseTLS decides whether connections use TLS. Unless enabled is set explicitly,
+// TLS is used as soon as a CA, a client certificate or insecure is configured.
+func UseTLS(enabled *bool, certFile, keyFile, caFile string, insecure bool) (bool, error) {
+	configured := certFile != "" || keyFile != "" || caFile != "" || insecure
+	if enabled == nil {
+		return configured, nil
+	}
+	if !*enabled && configured {
+		return false, errors.New("TLS is disabled, but certificates or insecure are configured")
+	}
+	return *enabled, nil
+}
+
+// NewTLSConfig creates the TLS config. The client certificate is optional (server-only TLS),
+// without a CA file the system CA certificates are used.
 func NewTLSConfig(certFile, keyFile, caFile string, insecure bool) (*tls.Config, error) {
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
-	if err != nil {
-		return nil, err
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("a client certificate requires a client key and vice versa")
 	}
 
 	if insecure {
@@ -22,10 +36,17 @@ func NewTLSConfig(certFile, keyFile, caFile string, insecure bool) (*tls.Config,
 
 	config := &tls.Config{
 		MinVersion:         tls.VersionTLS12,
-		Certificates:       []tls.Certificate{cert},
 		InsecureSkipVerify: insecure,
 	}
 
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
 	if caFile != "" {
 		caCertBytes, err := os.ReadFile(caFile)
 		if err != nil {
@@ -0,0 +1,162 @@
+package message
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// Filter selects messages with a jq expression
+type Filter struct {
+	code *gojq.Code
+}
+
+// NewFilter compiles a jq expression.
+// A message matches if the expression yields at least one value that is neither false nor null,
+// so both `.payload.status == "FAILED"` and `select(.payload.status == "FAILED")` can be used.
+func NewFilter(expression string) (*Filter, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse filter %q: %w", expression, err)
+	}
+
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile filter %q: %w", expression, err)
+	}
+
+	return &Filter{code: code}, nil
+}
+
+// Match reports whether the message matches the filter
+func (f *Filter) Match(msg Message) (bool, error) {
+	input, err := toJQValue(msg)
+	if err != nil {
+		return false, err
+	}
+
+	iter := f.code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			return false, nil
+		}
+		if err, ok := v.(error); ok {
+			return false, fmt.Errorf("failed to evaluate filter: %w", err)
+		}
+		if v != nil && v != false {
+			return true, nil
+		}
+	}
+}
+
+// toJQValue converts the message to the generic JSON types gojq operates on, with the field names of its JSON output
+func toJQValue(msg Message) (interface{}, error) {
+	key, err := jqValue(msg.Metadata.Key)
+	if err != nil {
+		return nil, err
+	}
+	metadata := map[string]interface{}{
+		"partition": int(msg.Metadata.Partition),
+		"offset":    int(msg.Metadata.Offset),
+		"timestamp": msg.Metadata.Timestamp,
+		"key":       key,
+	}
+	putNonZero(metadata, "keyEncoding", msg.Metadata.KeyEncoding)
+	if len(msg.Metadata.Headers) > 0 {
+		headers := make(map[string]interface{}, len(msg.Metadata.Headers))
+		for name, value := range msg.Metadata.Headers {
+			headers[name] = value
+		}
+		metadata["headers"] = headers
+	}
+
+	payload, err := jqValue(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	v := map[string]interface{}{
+		"schema":   schemaRefValue(msg.Schema),
+		"metadata": metadata,
+		"payload":  payload,
+	}
+	if msg.KeySchema != nil {
+		v["keySchema"] = schemaRefValue(*msg.KeySchema)
+	}
+	putNonZero(v, "payloadEncoding", msg.PayloadEncoding)
+	putNonZero(v, "error", msg.Error)
+	return v, nil
+}
+
+// putNonZero sets the value unless it is zero, like omitempty
+// schemaRefValue converts a schema reference like its JSON encoding, without empty fields
+func schemaRefValue(ref SchemaRef) map[string]interface{} {
+	v := map[string]interface{}{}
+	putNonZero(v, "id", ref.Id)
+	putNonZero(v, "name", ref.Name)
+	putNonZero(v, "namespace", ref.Namespace)
+	return v
+}
+
+func putNonZero[T comparable](m map[string]interface{}, name string, value T) {
+	var zero T
+	if value != zero {
+		m[name] = value
+	}
+}
+
+// jqValue converts a decoded payload to the types of gojq without encoding it.
+// Types without a direct equivalent are converted through their JSON encoding.
+func jqValue(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case nil, bool, string, int, float64:
+		return value, nil
+	case int32:
+		return int(value), nil
+	case int64:
+		return int(value), nil
+	case float32:
+		return float64(value), nil
+	case []byte:
+		// encoding/json writes bytes as base64
+		return base64.StdEncoding.EncodeToString(value), nil
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return int(n), nil
+		}
+		return value.Float64()
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for name, item := range value {
+			converted, err := jqValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = converted
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			converted, err := jqValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = converted
+		}
+		return result, nil
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message: %w", err)
+		}
+		var result interface{}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,124 @@
+package message
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"gokcat/internal/kafka/schemaRegistry"
+)
+
+// testMessages are messages as written by gokcat cat: an Avro payload, a JSON payload without schema and a text payload
+func testMessages() map[string]Message {
+	consumed := &sarama.ConsumerMessage{
+		Key:       []byte("k1"),
+		Partition: 1,
+		Offset:    7,
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("source"), Value: []byte("test")}},
+	}
+
+	schema := &schemaRegistry.Schema{ID: 42, Name: "Order", Namespace: "shop"}
+	avro := New(schema, map[string]interface{}{
+		"id":     int64(12345678901),
+		"count":  3,
+		"score":  float32(1.5),
+		"amount": "12.34",
+		"data":   []byte("hi"),
+		"tags":   []interface{}{"a", "b"},
+		"status": map[string]interface{}{"code": "FAILED"},
+	}, consumed)
+
+	var payload interface{}
+	decoder := json.NewDecoder(strings.NewReader(`{"count": 12, "ratio": 0.25, "items": [{"sku": "x"}]}`))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		panic(err)
+	}
+	plainJSON := New(nil, payload, consumed)
+
+	text := New(nil, "hello world", &sarama.ConsumerMessage{Partition: 0, Offset: 1})
+	text.PayloadEncoding = EncodingText
+
+	return map[string]Message{"avro": avro, "json": plainJSON, "text": text}
+}
+
+func TestFilterMatch(t *testing.T) {
+	messages := testMessages()
+
+	tests := []struct {
+		message    string
+		expression string
+		want       bool
+		wantErr    bool
+	}{
+		{"avro", `.payload.id == 12345678901`, true, false},
+		{"avro", `.payload.count > 2`, true, false},
+		{"avro", `.payload.score == 1.5`, true, false},
+		{"avro", `.payload.amount == "12.34"`, true, false},
+		{"avro", `.payload.data == "aGk="`, true, false},
+		{"avro", `.payload.tags | index("b") == 1`, true, false},
+		{"avro", `select(.payload.status.code == "FAILED")`, true, false},
+		{"avro", `.schema.id == 42 and .schema.name == "Order"`, true, false},
+		{"avro", `.metadata.key == "k1" and .metadata.headers.source == "test"`, true, false},
+		{"avro", `.payload.count > 3`, false, false},
+		{"json", `.payload.count > 10 and .payload.ratio < 1`, true, false},
+		{"json", `.payload.items[0].sku == "x"`, true, false},
+		{"json", `.schema == {}`, true, false},
+		{"text", `.payload | startswith("hello")`, true, false},
+		{"text", `.payloadEncoding == "text"`, true, false},
+		{"text", `.metadata.key == null`, true, false},
+		{"text", `.payload.id`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message+" "+tt.expression, func(t *testing.T) {
+			filter, err := NewFilter(tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := filter.Match(messages[tt.message])
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Match() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFilterInput checks that filters see the message as it is written as JSON
+func TestFilterInput(t *testing.T) {
+	for name, msg := range testMessages() {
+		t.Run(name, func(t *testing.T) {
+			input, err := toJQValue(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := json.Marshal(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(want, &wantValue); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Fatalf("filter input = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/IBM/sarama"
 	"gokcat/internal/kafka/schemaRegistry"
 	"time"
+	"unicode/utf8"
 )
 
 type T struct {
@@ -15,33 +16,65 @@ type T struct {
 	} `json:"headers"`
 }
 
+const (
+	// EncodingText marks a payload that is text but not JSON, it is written as a string
+	EncodingText = "text"
+	// EncodingBase64 marks a payload or key that is neither JSON nor text, it is written as base64 of the raw bytes
+	EncodingBase64 = "base64"
+	// EncodingEscapedJSON marks a payload that was written as escaped JSON, e.g. {\"id\":1}
+	EncodingEscapedJSON = "escaped-json"
+)
+
+// SchemaRef identifies the schema of a payload or key, it is shared by the JSON output, templates and filters
+type SchemaRef struct {
+	Id        int    `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
 type Message struct {
-	Schema struct {
-		Id        int    `json:"id,omitempty"`
-		Name      string `json:"name,omitempty"`
-		Namespace string `json:"namespace,omitempty"`
-	} `json:"schema,omitempty"`
+	Schema SchemaRef `json:"schema,omitempty"`
+
+	KeySchema *SchemaRef `json:"keySchema,omitempty"`
 
 	Metadata struct {
-		Offset    int64             `json:"offset"`
-		Timestamp string            `json:"timestamp"`
-		Key       string            `json:"key"`
-		Headers   map[string]string `json:"headers,omitempty"`
+		Partition int32       `json:"partition"`
+		Offset    int64       `json:"offset"`
+		Timestamp string      `json:"timestamp"`
+		Key       interface{} `json:"key"`
+		// KeyEncoding is EncodingBase64 for keys that are not valid UTF-8
+		KeyEncoding string            `json:"keyEncoding,omitempty"`
+		Headers     map[string]string `json:"headers,omitempty"`
 	} `json:"metadata,omitempty"`
 
 	Payload interface{} `json:"payload"`
+	// PayloadEncoding tells how a payload without schema is written if it is not JSON, see EncodingBase64
+	PayloadEncoding string `json:"payloadEncoding,omitempty"`
+
+	// Error reports a problem with the payload, e.g. a schema validation failure
+	Error string `json:"error,omitempty"`
+
+	timestamp time.Time
 }
 
 func New(schema *schemaRegistry.Schema, payloadData interface{}, msg *sarama.ConsumerMessage) Message {
 	out := Message{}
 	if schema != nil {
-		out.Schema.Id = schema.ID
-		out.Schema.Name = schema.Name
-		out.Schema.Namespace = schema.Namespace
+		out.Schema = newSchemaRef(schema)
+	}
+	switch {
+	case msg.Key == nil:
+		// messages without a key have a null key
+	case utf8.Valid(msg.Key):
+		out.Metadata.Key = string(msg.Key)
+	default:
+		out.Metadata.Key = msg.Key
+		out.Metadata.KeyEncoding = EncodingBase64
 	}
-	out.Metadata.Key = string(msg.Key)
 	out.Metadata.Timestamp = msg.Timestamp.Format(time.RFC3339)
+	out.Metadata.Partition = msg.Partition
 	out.Metadata.Offset = msg.Offset
+	out.timestamp = msg.Timestamp
 
 	if msg.Headers != nil {
 		out.Metadata.Headers = make(map[string]string, len(msg.Headers))
@@ -57,3 +90,21 @@ func New(schema *schemaRegistry.Schema, payloadData interface{}, msg *sarama.Con
 
 	return out
 }
+
+// SetKey replaces the raw key with the key decoded by the given schema
+func (m *Message) SetKey(schema *schemaRegistry.Schema, key interface{}) {
+	m.Metadata.Key = key
+	m.Metadata.KeyEncoding = ""
+	if schema != nil {
+		ref := newSchemaRef(schema)
+		m.KeySchema = &ref
+	}
+}
+
+func newSchemaRef(schema *schemaRegistry.Schema) SchemaRef {
+	return SchemaRef{
+		Id:        schema.ID,
+		Name:      schema.Name,
+		Namespace: schema.Namespace,
+	}
+}
@@ -0,0 +1,188 @@
+package message
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the data passed to output templates
+type TemplateData struct {
+	Partition int32
+	Offset    int64
+	Key       interface{}
+	Headers   map[string]string
+	Timestamp time.Time
+	Schema    SchemaRef
+	KeySchema *SchemaRef
+	Payload   interface{}
+	Error     string
+}
+
+var templateFuncs = template.FuncMap{
+	"json":   toJSON,
+	"base64": toBase64,
+	"hex":    toHex,
+	"time":   formatTime,
+}
+
+// NewTemplateWriter creates a writer that renders each message through a Go text/template.
+// Escape sequences like \n and \t are interpreted outside of actions, and a newline is added if the format does not end with one.
+func NewTemplateWriter(format string, out io.Writer) (Writer, error) {
+	format = unescape(format)
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=zero").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse format template: %w", err)
+	}
+
+	return &templateWriter{out: out, tmpl: tmpl}, nil
+}
+
+type templateWriter struct {
+	out  io.Writer
+	tmpl *template.Template
+}
+
+func (w *templateWriter) Write(msg Message) error {
+	data := TemplateData{
+		Partition: msg.Metadata.Partition,
+		Offset:    msg.Metadata.Offset,
+		Key:       msg.Metadata.Key,
+		Headers:   msg.Metadata.Headers,
+		Timestamp: msg.timestamp,
+		Schema:    msg.Schema,
+		KeySchema: msg.KeySchema,
+		Payload:   msg.Payload,
+		Error:     msg.Error,
+	}
+	// Messages without a key have a null key in JSON, templates print them as empty
+	if data.Key == nil {
+		data.Key = ""
+	}
+	if raw, ok := msg.Payload.([]byte); ok {
+		data.Payload = string(raw)
+	}
+
+	if err := w.tmpl.Execute(w.out, data); err != nil {
+		return fmt.Errorf("failed to render message: %w", err)
+	}
+	return nil
+}
+
+func (w *templateWriter) Close() error {
+	return nil
+}
+
+var escapes = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\r`, "\r", `\\`, `\`)
+
+// unescape interprets escape sequences in the text of the template, actions {{...}} are kept as they are
+// so that string literals like {{printf "%s\n" .Key}} keep their Go escapes.
+func unescape(format string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(format, "{{")
+		if start < 0 {
+			b.WriteString(escapes.Replace(format))
+			return b.String()
+		}
+		b.WriteString(escapes.Replace(format[:start]))
+
+		end := actionEnd(format, start+2)
+		b.WriteString(format[start:end])
+		format = format[end:]
+	}
+}
+
+// actionEnd returns the index after the "}}" closing the action starting at i, skipping string literals and comments
+func actionEnd(format string, i int) int {
+	var quote byte
+	for ; i < len(format); i++ {
+		c := format[i]
+		switch {
+		case quote == 0 && strings.HasPrefix(format[i:], "/*"):
+			end := strings.Index(format[i+2:], "*/")
+			if end < 0 {
+				return len(format)
+			}
+			i += 2 + end + 1
+		case (quote == '"' || quote == '\'') && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`' || c == '\'':
+			quote = c
+		case strings.HasPrefix(format[i:], "}}"):
+			return i + 2
+		}
+	}
+	return len(format)
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func toBytes(v interface{}) []byte {
+	switch value := v.(type) {
+	case []byte:
+		return value
+	case string:
+		return []byte(value)
+	default:
+		return []byte(fmt.Sprint(value))
+	}
+}
+
+func toBase64(v interface{}) string {
+	return base64.StdEncoding.EncodeToString(toBytes(v))
+}
+
+func toHex(v interface{}) string {
+	return hex.EncodeToString(toBytes(v))
+}
+
+// formatTime formats a time with a Go layout or one of the names rfc3339, rfc3339nano, unix and unixmilli.
+// Besides time.Time it accepts RFC3339 strings as rendered for Avro timestamps.
+func formatTime(layout string, v interface{}) (string, error) {
+	var t time.Time
+	switch value := v.(type) {
+	case time.Time:
+		t = value
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return "", err
+		}
+		t = parsed
+	default:
+		return "", fmt.Errorf("expected a time, got %T", v)
+	}
+
+	switch strings.ToLower(layout) {
+	case "rfc3339":
+		return t.Format(time.RFC3339), nil
+	case "rfc3339nano":
+		return t.Format(time.RFC3339Nano), nil
+	case "unix":
+		return fmt.Sprint(t.Unix()), nil
+	case "unixmilli":
+		return fmt.Sprint(t.UnixMilli()), nil
+	default:
+		return t.Format(layout), nil
+	}
+}
@@ -0,0 +1,73 @@
+package message
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{"text escapes", `a\tb\n`, "a\tb\n"},
+		{"escaped backslash", `a\\n`, `a\n`},
+		{"escape in string literal", `{{printf "%s\n" .Key}}\n`, "{{printf \"%s\\n\" .Key}}\n"},
+		{"escaped quote in string literal", `{{printf "\"}}\n" .Key}}\t`, "{{printf \"\\\"}}\\n\" .Key}}\t"},
+		{"raw string", "{{printf `%s\\n}}` .Key}}\\n", "{{printf `%s\\n}}` .Key}}\n"},
+		{"char literal", `{{if eq .Key '}'}}\t{{end}}`, "{{if eq .Key '}'}}\t{{end}}"},
+		{"comment with quote", `{{/* "}} */}}\t{{.Key}}`, "{{/* \"}} */}}\t{{.Key}}"},
+		{"comment with trim markers", `{{- /* it's }} */ -}}\n`, "{{- /* it's }} */ -}}\n"},
+		{"unclosed action", `\t{{.Key`, "\t{{.Key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unescape(tt.format); got != tt.want {
+				t.Fatalf("unescape(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateWriter(t *testing.T) {
+	msg := New(nil, map[string]interface{}{"id": 1}, &sarama.ConsumerMessage{
+		Key:       []byte("k1"),
+		Partition: 2,
+		Offset:    42,
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{"fields", `{{.Partition}}/{{.Offset}} {{.Key}}`, "2/42 k1\n"},
+		{"newline in printf", `{{printf "%s\n" .Key}}`, "k1\n\n"},
+		{"escaped quote", `{{printf "\"%s\"" .Key}}`, "\"k1\"\n"},
+		{"raw string", "{{printf `%s\\t` .Key}}", "k1\\t\n"},
+		{"comment with quote", `{{/* "}} */}}{{.Key}}\t{{json .Payload}}`, "k1\t{\"id\":1}\n"},
+		{"time", `{{time "unix" .Timestamp}}`, "1704164645\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			writer, err := NewTemplateWriter(tt.format, &out)
+			if err != nil {
+				t.Fatalf("NewTemplateWriter() error = %v", err)
+			}
+			if err := writer.Write(msg); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Fatalf("Write() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// OutputJSONArray writes all messages as one indented JSON array
+	OutputJSONArray = "json-array"
+	// OutputNDJSON writes one JSON document per line
+	OutputNDJSON = "ndjson"
+	// OutputCompact writes a JSON array with one message per line
+	OutputCompact = "compact"
+)
+
+// OutputModes lists all supported output modes
+var OutputModes = []string{OutputJSONArray, OutputNDJSON, OutputCompact}
+
+// Writer writes messages to an output stream
+type Writer interface {
+	Write(msg Message) error
+	// Close terminates the output, e.g. by closing a JSON array
+	Close() error
+}
+
+// NewWriter creates a writer for the given output mode
+func NewWriter(mode string, out io.Writer) (Writer, error) {
+	switch mode {
+	case OutputJSONArray:
+		return &arrayWriter{out: out, indent: true}, nil
+	case OutputCompact:
+		return &arrayWriter{out: out}, nil
+	case OutputNDJSON:
+		return &ndjsonWriter{out: out}, nil
+	default:
+		return nil, fmt.Errorf("unknown output mode %q, expected one of %v", mode, OutputModes)
+	}
+}
+
+type arrayWriter struct {
+	out    io.Writer
+	indent bool
+	count  int
+}
+
+func (w *arrayWriter) Write(msg Message) error {
+	var data []byte
+	var err error
+	if w.indent {
+		data, err = json.MarshalIndent(msg, "", "  ")
+	} else {
+		data, err = json.Marshal(msg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	separator := ",\n"
+	if w.count == 0 {
+		separator = "[\n"
+	}
+	w.count++
+
+	_, err = fmt.Fprint(w.out, separator, string(data))
+	return err
+}
+
+func (w *arrayWriter) Close() error {
+	if w.count == 0 {
+		_, err := fmt.Fprintln(w.out, "[]")
+		return err
+	}
+	_, err := fmt.Fprint(w.out, "\n]\n")
+	return err
+}
+
+type ndjsonWriter struct {
+	out io.Writer
+}
+
+func (w *ndjsonWriter) Write(msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w.out, string(data))
+	return err
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}